@@ -0,0 +1,88 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"strings"
+	"sync"
+)
+
+// AddressIndex maps token contract addresses to CoinMarketCap ids.
+//
+// It is filled from cryptocurrency/map and cryptocurrency/info results, the
+// latter listing contracts on every chain a token is issued on. EVM addresses
+// are matched case-insensitively, other addresses exactly.
+type AddressIndex struct {
+	mu  sync.RWMutex
+	ids map[string][]int
+}
+
+// NewAddressIndex returns an empty AddressIndex.
+func NewAddressIndex() *AddressIndex {
+	return &AddressIndex{ids: map[string][]int{}}
+}
+
+// Add maps the address to the id.
+func (x *AddressIndex) Add(address string, id int) {
+	if address == "" {
+		return
+	}
+	key := normAddress(address)
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, v := range x.ids[key] {
+		if v == id {
+			return
+		}
+	}
+	x.ids[key] = append(x.ids[key], id)
+}
+
+// AddMap adds platform token addresses of the currencies.
+func (x *AddressIndex) AddMap(currencies []CurrencyMap) {
+	for _, c := range currencies {
+		if c.Platform != nil {
+			x.Add(c.Platform.TokenAddress, c.Id)
+		}
+	}
+}
+
+// AddInfo adds contract and platform token addresses of the currencies.
+func (x *AddressIndex) AddInfo(info CurrencyInfoMap) {
+	for _, c := range info {
+		for _, contract := range c.ContractAddress {
+			x.Add(contract.ContractAddress, c.Id)
+		}
+		if c.Platform != nil {
+			x.Add(c.Platform.TokenAddress, c.Id)
+		}
+	}
+}
+
+// Lookup returns ids of tokens with the address, in the order they were added.
+// The same address may be used by different tokens on different chains.
+func (x *AddressIndex) Lookup(address string) []int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return append([]int(nil), x.ids[normAddress(address)]...)
+}
+
+// Len returns the number of indexed addresses.
+func (x *AddressIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.ids)
+}
+
+// normAddress lowercases EVM addresses, which are hex and case-insensitive.
+func normAddress(address string) string {
+	address = strings.TrimSpace(address)
+	lower := strings.ToLower(address)
+	if len(lower) == 42 && strings.HasPrefix(lower, "0x") &&
+		strings.Trim(lower[2:], "0123456789abcdef") == "" {
+		return lower
+	}
+	return address
+}
@@ -0,0 +1,61 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"testing"
+)
+
+const usdtAddress = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+
+func TestAddressIndex(t *testing.T) {
+	x := NewAddressIndex()
+	x.AddInfo(CurrencyInfoMap{
+		"825": {Id: 825, ContractAddress: []CurrencyContract{
+			{ContractAddress: usdtAddress},
+			{ContractAddress: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"},
+		}},
+		"3408": {Id: 3408, Platform: &CurrencyPlatform{
+			TokenAddress: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"}},
+	})
+	x.AddMap([]CurrencyMap{{Id: 825, Platform: &CurrencyPlatform{TokenAddress: usdtAddress}}})
+	for address, id := range map[string]int{
+		usdtAddress: 825,
+		"0xdac17f958d2ee523a2206206994597c13d831ec7":   825,
+		"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t":           825,
+		"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": 3408,
+	} {
+		if ids := x.Lookup(address); len(ids) != 1 || ids[0] != id {
+			t.Errorf("Lookup(%s) = %v, want %d", address, ids, id)
+		}
+	}
+	if ids := x.Lookup("tr7nhqjekqxgtci8q8zy4pl8otszgjlj6t"); len(ids) != 0 {
+		t.Errorf("non-EVM address matched case-insensitively: %v", ids)
+	}
+	if x.Len() != 3 {
+		t.Errorf("Len() = %d", x.Len())
+	}
+}
+
+func TestGetCurrencyInfoByAddress(t *testing.T) {
+	cmc, _ := NewTest()
+	info, err := cmc.GetCurrencyInfoByAddress("0xdac17f958d2ee523a2206206994597c13d831ec7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	usdt, ok := info["825"]
+	if !ok || len(usdt.ContractAddress) != 1 || usdt.ContractAddress[0].ContractAddress != usdtAddress ||
+		usdt.ContractAddress[0].Platform.Coin.Id != "1027" {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	x := NewAddressIndex()
+	x.AddInfo(info)
+	if ids := x.Lookup(usdtAddress); len(ids) != 1 || ids[0] != 825 {
+		t.Errorf("Lookup() = %v", ids)
+	}
+	if _, err = (&CurrencyInfoQuery{Ids: []int{825}, Address: usdtAddress}).Values(); err == nil {
+		t.Error("expected error for address with id")
+	}
+}
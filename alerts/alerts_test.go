@@ -0,0 +1,117 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+	"github.com/nikchis/cmc-proapi/poller"
+)
+
+func TestParse(t *testing.T) {
+	for s, want := range map[string]Rule{
+		"BTC price > 70000 USD":      {Asset: "BTC", Field: "price", Op: Above, Threshold: 70000, Convert: "USD"},
+		"ETH PercentChange24h < -10": {Asset: "ETH", Field: "PercentChange24h", Op: Below, Threshold: -10},
+		"BTC dominance crosses 55":   {Field: "btc_dominance", Op: Crosses, Threshold: 55},
+		"global total_market_cap crosses below 2e12 eur": {
+			Field: "total_market_cap", Op: CrossesBelow, Threshold: 2e12, Convert: "EUR"},
+	} {
+		r, err := Parse(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+		want.Name = s
+		if r != want {
+			t.Errorf("%s: got %+v, want %+v", s, r, want)
+		}
+	}
+	for _, s := range []string{"BTC price", "BTC colour > 1", "BTC price ~ 1", "BTC price > x"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func tick(price float64, at time.Time) poller.Tick {
+	quote := cmcproapi.CurrencyQuoteMap{"USD": {Price: price}}
+	return poller.Tick{Time: at, Updates: []poller.Update{{
+		Key:     "1",
+		Current: cmcproapi.CurrencyListing{Id: 1, Symbol: "BTC", Quote: &quote},
+	}}}
+}
+
+func TestHysteresis(t *testing.T) {
+	e := New()
+	e.Add(Rule{Asset: "BTC", Field: "price", Op: Above, Threshold: 100, Hysteresis: 5})
+	now := time.Now()
+	for i, step := range []struct {
+		price float64
+		fired int
+	}{
+		{90, 0},
+		{101, 1},
+		{98, 0},
+		{101, 0}, // not re-armed, price stayed within hysteresis
+		{94, 0},
+		{102, 1},
+	} {
+		now = now.Add(time.Minute)
+		if n := len(e.Evaluate(context.Background(), tick(step.price, now))); n != step.fired {
+			t.Errorf("step %d price %v: got %d alerts", i, step.price, n)
+		}
+	}
+}
+
+func TestCrosses(t *testing.T) {
+	e := New()
+	e.Add(Rule{Asset: "BTC", Field: "price", Op: Crosses, Threshold: 100, Cooldown: time.Hour})
+	now := time.Now()
+	var n int
+	for _, price := range []float64{90, 110, 90, 110} {
+		now = now.Add(time.Minute)
+		n += len(e.Evaluate(context.Background(), tick(price, now)))
+	}
+	if n != 1 {
+		t.Errorf("expected single alert within cooldown, got %d", n)
+	}
+}
+
+func TestAttachWebhook(t *testing.T) {
+	received := make(chan Alert, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a Alert
+		json.NewDecoder(r.Body).Decode(&a)
+		received <- a
+	}))
+	defer hook.Close()
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	cmc, _ := cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, cmcproapi.ApiVersion,
+		&http.Client{}, time.Second)
+	p := poller.New(cmc, poller.Options{Global: true})
+	p.Watch(1)
+	e := New(WebhookSink{URL: hook.URL})
+	r, _ := Parse("BTC dominance > 50")
+	e.Add(r)
+	e.Attach(p)
+	p.Start(context.Background())
+	defer p.Stop()
+	select {
+	case a := <-received:
+		if a.Field != "btc_dominance" || a.Value < 50 {
+			t.Errorf("unexpected alert %+v", a)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no alert received")
+	}
+}
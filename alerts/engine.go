@@ -0,0 +1,216 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+// Package alerts evaluates declarative rules against quotes delivered by
+// the poller and dispatches fired alerts to sinks.
+//
+//	engine := alerts.New(alerts.LogSink{}, alerts.WebhookSink{URL: hook})
+//	rule, _ := alerts.Parse("BTC price > 70000 USD")
+//	engine.Add(rule)
+//	engine.Attach(p)
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nikchis/cmc-proapi/poller"
+)
+
+// Alert is a fired rule.
+type Alert struct {
+	Rule     Rule      `json:"-"`
+	Name     string    `json:"name"`
+	Asset    string    `json:"asset,omitempty"`
+	Field    string    `json:"field"`
+	Convert  string    `json:"convert,omitempty"`
+	Value    float64   `json:"value"`
+	Previous float64   `json:"previous"`
+	Time     time.Time `json:"time"`
+	Message  string    `json:"message"`
+}
+
+// state of a rule between ticks.
+type state struct {
+	rule     Rule
+	accessor accessor
+	known    bool
+	active   bool
+	value    float64
+	fired    time.Time
+}
+
+// Engine evaluates rules and dispatches alerts to sinks.
+type Engine struct {
+	// OnError is called when a sink fails, errors are ignored if nil.
+	OnError func(error)
+
+	mu     sync.Mutex
+	states []*state
+	sinks  []Sink
+}
+
+// New returns an Engine dispatching alerts to sinks.
+func New(sinks ...Sink) *Engine {
+	return &Engine{sinks: sinks}
+}
+
+// Add validates and adds rules.
+func (e *Engine) Add(rules ...Rule) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range rules {
+		a, err := r.accessor()
+		if err != nil {
+			return err
+		}
+		if r.Convert == "" {
+			r.Convert = "USD"
+		}
+		if r.Name == "" {
+			r.Name = r.String()
+		}
+		e.states = append(e.states, &state{rule: r, accessor: a})
+	}
+	return nil
+}
+
+// Attach evaluates every tick delivered by the poller.
+func (e *Engine) Attach(p *poller.Poller) {
+	p.OnTick(func(tick poller.Tick) {
+		e.Evaluate(context.Background(), tick)
+	})
+}
+
+// Evaluate applies rules to the tick, dispatches fired alerts and returns them.
+func (e *Engine) Evaluate(ctx context.Context, tick poller.Tick) (alerts []Alert) {
+	now := tick.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	e.mu.Lock()
+	for _, st := range e.states {
+		if st.accessor.global {
+			if tick.Global == nil {
+				continue
+			}
+			if v, ok := st.accessor.globalValue(*tick.Global, st.rule.Convert); ok {
+				if a, fired := st.update(v, now); fired {
+					alerts = append(alerts, a)
+				}
+			}
+			continue
+		}
+		for _, u := range tick.Updates {
+			if !matches(st.rule.Asset, u) {
+				continue
+			}
+			if v, ok := st.accessor.listingValue(u.Current, st.rule.Convert); ok {
+				if a, fired := st.update(v, now); fired {
+					a.Asset = u.Current.Symbol
+					alerts = append(alerts, a)
+				}
+			}
+		}
+	}
+	sinks := e.sinks
+	e.mu.Unlock()
+	for _, a := range alerts {
+		for _, s := range sinks {
+			if err := s.Send(ctx, a); err != nil && e.OnError != nil {
+				e.OnError(err)
+			}
+		}
+	}
+	return
+}
+
+// matches reports whether asset refers to the updated cryptocurrency.
+func matches(asset string, u poller.Update) bool {
+	return strings.EqualFold(asset, u.Key) || strings.EqualFold(asset, u.Current.Symbol) ||
+		asset == strconv.Itoa(u.Current.Id) || strings.EqualFold(asset, u.Current.Slug)
+}
+
+// update applies the value to the rule state and reports whether the rule fired.
+func (st *state) update(v float64, now time.Time) (a Alert, fired bool) {
+	r := st.rule
+	prev, known := st.value, st.known
+	st.value, st.known = v, true
+	switch r.Op {
+	case Above, AboveOrEqual, Below, BelowOrEqual:
+		cond := compare(r.Op, v, r.Threshold)
+		if st.active {
+			// re-arm only once the value is back beyond the hysteresis band
+			if !cond && !compare(r.Op, v, r.Threshold-r.Hysteresis*sign(r.Op)) {
+				st.active = false
+			}
+			return
+		}
+		if !cond {
+			return
+		}
+		st.active = true
+	default:
+		above := st.active
+		switch {
+		case v > r.Threshold+r.Hysteresis:
+			above = true
+		case v < r.Threshold-r.Hysteresis:
+			above = false
+		}
+		if !known {
+			st.active = v > r.Threshold
+			return
+		}
+		if above == st.active {
+			return
+		}
+		st.active = above
+		if (r.Op == CrossesAbove && !above) || (r.Op == CrossesBelow && above) {
+			return
+		}
+	}
+	if r.Cooldown > 0 && !st.fired.IsZero() && now.Sub(st.fired) < r.Cooldown {
+		return
+	}
+	st.fired = now
+	a = Alert{
+		Rule:     r,
+		Name:     r.Name,
+		Asset:    r.Asset,
+		Field:    r.Field,
+		Convert:  r.Convert,
+		Value:    v,
+		Previous: prev,
+		Time:     now,
+	}
+	a.Message = fmt.Sprintf("%s: %s is %s", r.Name, r.Field, strconv.FormatFloat(v, 'f', -1, 64))
+	return a, true
+}
+
+func compare(op Operator, v, threshold float64) bool {
+	switch op {
+	case Above:
+		return v > threshold
+	case AboveOrEqual:
+		return v >= threshold
+	case Below:
+		return v < threshold
+	case BelowOrEqual:
+		return v <= threshold
+	}
+	return false
+}
+
+// sign returns the direction of the operator, the hysteresis band lies opposite to it.
+func sign(op Operator) float64 {
+	if op == Below || op == BelowOrEqual {
+		return -1
+	}
+	return 1
+}
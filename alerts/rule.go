@@ -0,0 +1,206 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+)
+
+// Operator compares the value of a field with the threshold of a rule.
+type Operator string
+
+const (
+	Above        Operator = ">"
+	AboveOrEqual Operator = ">="
+	Below        Operator = "<"
+	BelowOrEqual Operator = "<="
+	Crosses      Operator = "crosses"
+	CrossesAbove Operator = "crosses above"
+	CrossesBelow Operator = "crosses below"
+)
+
+// Rule describes when an alert fires.
+//
+// Asset is a symbol or id of a cryptocurrency, an empty Asset refers to global
+// metrics. Field is a numeric field of CurrencyQuote, CurrencyListing,
+// GlobalQuote or GlobalMetrics given by its JSON or Go name, e.g. price or
+// PercentChange24h. Quote fields are read in the Convert currency, USD by default.
+//
+// Comparison rules fire when the condition becomes true and re-arm when it
+// turns false by more than Hysteresis. Crossing rules fire when the value moves
+// to the other side of Threshold by more than Hysteresis. No rule fires more
+// than once per Cooldown.
+type Rule struct {
+	Name       string
+	Asset      string
+	Field      string
+	Convert    string
+	Op         Operator
+	Threshold  float64
+	Hysteresis float64
+	Cooldown   time.Duration
+}
+
+// String returns the rule in the form accepted by Parse.
+func (r Rule) String() string {
+	asset := r.Asset
+	if asset == "" {
+		asset = "global"
+	}
+	s := fmt.Sprintf("%s %s %s %s", asset, r.Field, r.Op, strconv.FormatFloat(r.Threshold, 'f', -1, 64))
+	if r.Convert != "" {
+		s += " " + r.Convert
+	}
+	return s
+}
+
+// Parse returns a rule from its textual form, e.g.
+//
+//	BTC price > 70000 USD
+//	ETH PercentChange24h < -10
+//	BTC dominance crosses 55
+//	global total_market_cap crosses below 2e12 EUR
+func Parse(s string) (r Rule, err error) {
+	fields := strings.Fields(s)
+	if len(fields) < 4 {
+		return r, errors.New("alerts: rule must be in form <asset> <field> <operator> <value> [convert]")
+	}
+	r.Name = s
+	r.Asset, r.Field, fields = fields[0], fields[1], fields[2:]
+	r.Op = Operator(strings.ToLower(fields[0]))
+	fields = fields[1:]
+	if r.Op == Crosses && len(fields) > 1 {
+		if dir := strings.ToLower(fields[0]); dir == "above" || dir == "below" {
+			r.Op = Operator("crosses " + dir)
+			fields = fields[1:]
+		}
+	}
+	if r.Threshold, err = strconv.ParseFloat(strings.Replace(fields[0], ",", "", -1), 64); err != nil {
+		return r, fmt.Errorf("alerts: invalid value %q", fields[0])
+	}
+	switch len(fields) {
+	case 1:
+	case 2:
+		r.Convert = strings.ToUpper(fields[1])
+	default:
+		return r, fmt.Errorf("alerts: unexpected %q", strings.Join(fields[2:], " "))
+	}
+	if strings.EqualFold(r.Asset, "global") {
+		r.Asset = ""
+	}
+	// BTC dominance and ETH dominance are global metrics
+	if strings.EqualFold(r.Field, "dominance") && r.Asset != "" {
+		r.Field = strings.ToLower(r.Asset) + "_dominance"
+		r.Asset = ""
+	}
+	_, err = r.accessor()
+	return
+}
+
+// accessor of a numeric field in one of the API types.
+type accessor struct {
+	global bool
+	quote  bool
+	index  []int
+}
+
+var (
+	listingType     = reflect.TypeOf(cmcproapi.CurrencyListing{})
+	quoteType       = reflect.TypeOf(cmcproapi.CurrencyQuote{})
+	globalType      = reflect.TypeOf(cmcproapi.GlobalMetrics{})
+	globalQuoteType = reflect.TypeOf(cmcproapi.GlobalQuote{})
+)
+
+// findField returns index of a numeric field of t matching name.
+func findField(t reflect.Type, name string) ([]int, bool) {
+	norm := strings.ToLower(strings.Replace(name, "_", "", -1))
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if k := f.Type.Kind(); k != reflect.Float64 && k != reflect.Int {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if strings.ToLower(f.Name) == norm || tag == strings.ToLower(name) {
+			return f.Index, true
+		}
+	}
+	return nil, false
+}
+
+// accessor resolves Field of the rule.
+func (r Rule) accessor() (a accessor, err error) {
+	var ok bool
+	switch r.Op {
+	case Above, AboveOrEqual, Below, BelowOrEqual, Crosses, CrossesAbove, CrossesBelow:
+	default:
+		return a, fmt.Errorf("alerts: unsupported operator %q", r.Op)
+	}
+	if r.Asset == "" {
+		a.global = true
+		if a.index, ok = findField(globalType, r.Field); ok {
+			return
+		}
+		a.quote = true
+		if a.index, ok = findField(globalQuoteType, r.Field); ok {
+			return
+		}
+	} else {
+		a.quote = true
+		if a.index, ok = findField(quoteType, r.Field); ok {
+			return
+		}
+		a.quote = false
+		if a.index, ok = findField(listingType, r.Field); ok {
+			return
+		}
+	}
+	return a, fmt.Errorf("alerts: unknown field %q", r.Field)
+}
+
+// value returns the field of v, which is a struct of the matching type.
+func (a accessor) value(v reflect.Value) float64 {
+	f := v.FieldByIndex(a.index)
+	if f.Kind() == reflect.Int {
+		return float64(f.Int())
+	}
+	return f.Float()
+}
+
+// listingValue returns the field of the listing.
+func (a accessor) listingValue(l cmcproapi.CurrencyListing, convert string) (float64, bool) {
+	if !a.quote {
+		return a.value(reflect.ValueOf(l)), true
+	}
+	if l.Quote == nil {
+		return 0, false
+	}
+	q, ok := (*l.Quote)[convert]
+	if !ok {
+		return 0, false
+	}
+	return a.value(reflect.ValueOf(q)), true
+}
+
+// globalValue returns the field of the global metrics.
+func (a accessor) globalValue(g cmcproapi.GlobalMetrics, convert string) (float64, bool) {
+	if !a.quote {
+		return a.value(reflect.ValueOf(g)), true
+	}
+	if g.Quote == nil {
+		return 0, false
+	}
+	q, ok := (*g.Quote)[convert]
+	if !ok {
+		return 0, false
+	}
+	return a.value(reflect.ValueOf(q)), true
+}
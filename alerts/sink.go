@@ -0,0 +1,92 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// Sink receives fired alerts.
+type Sink interface {
+	Send(ctx context.Context, a Alert) error
+}
+
+// SinkFunc adapts a function to Sink.
+type SinkFunc func(ctx context.Context, a Alert) error
+
+// Send calls f.
+func (f SinkFunc) Send(ctx context.Context, a Alert) error {
+	return f(ctx, a)
+}
+
+// LogSink prints alerts to Logger, the standard logger is used if nil.
+type LogSink struct {
+	Logger *log.Logger
+}
+
+// Send prints the alert message.
+func (s LogSink) Send(ctx context.Context, a Alert) error {
+	if s.Logger == nil {
+		log.Println(a.Message)
+		return nil
+	}
+	s.Logger.Println(a.Message)
+	return nil
+}
+
+// WebhookSink posts alerts as JSON to URL with optional extra Header.
+type WebhookSink struct {
+	URL    string
+	Header http.Header
+	Client *http.Client
+}
+
+// Send posts the alert and fails unless the response status is 2xx.
+func (s WebhookSink) Send(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range s.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("alerts: webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// ChannelSink delivers alerts to a channel, blocking until it is received
+// or the context is done.
+type ChannelSink chan<- Alert
+
+// Send delivers the alert to the channel.
+func (s ChannelSink) Send(ctx context.Context, a Alert) error {
+	select {
+	case s <- a:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,252 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	BatchSizeDefault        = 100
+	BatchConcurrencyDefault = 4
+	BatchQueryMaxLen        = 1800
+)
+
+// BatchOptions controls how long id or symbol lists are split and requested.
+//
+// Size is the maximum number of ids or symbols per request and Concurrency is
+// the number of requests run at once, they default to BatchSizeDefault and
+// BatchConcurrencyDefault when zero. Concurrent requests still respect the
+// RateLimit of the Client.
+type BatchOptions struct {
+	Size        int
+	Concurrency int
+}
+
+// BatchError describes the failure of a single batch of either ids or symbols.
+type BatchError struct {
+	Ids     []int
+	Symbols []string
+	Err     error
+}
+
+func (e *BatchError) Error() string {
+	switch {
+	case len(e.Ids) != 0:
+		return fmt.Sprintf("batch of %d ids starting with %d: %v", len(e.Ids), e.Ids[0], e.Err)
+	case len(e.Symbols) != 0:
+		return fmt.Sprintf("batch of %d symbols starting with %s: %v", len(e.Symbols), e.Symbols[0], e.Err)
+	}
+	return fmt.Sprintf("empty batch: %v", e.Err)
+}
+
+// BatchErrors is returned alongside the merged result of successful batches
+// when one or more batches have failed.
+type BatchErrors []*BatchError
+
+func (e BatchErrors) Error() string {
+	list := make([]string, len(e))
+	for i, err := range e {
+		list[i] = err.Error()
+	}
+	return strings.Join(list, "; ")
+}
+
+// splitIds deduplicates ids and splits them into batches bounded both by size
+// and by the length of the resulting comma-separated query value.
+func splitIds(ids []int, size int) (batches [][]int) {
+	if size <= 0 {
+		size = BatchSizeDefault
+	}
+	seen := make(map[int]bool, len(ids))
+	var batch []int
+	length := 0
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		l := len(strconv.Itoa(id)) + 1
+		if len(batch) == size || length+l > BatchQueryMaxLen {
+			batches = append(batches, batch)
+			batch, length = nil, 0
+		}
+		batch = append(batch, id)
+		length += l
+	}
+	if len(batch) != 0 {
+		batches = append(batches, batch)
+	}
+	return
+}
+
+// splitSymbols deduplicates upper-cased symbols, drops blank ones and splits
+// them into batches like splitIds.
+func splitSymbols(symbols []string, size int) (batches [][]string) {
+	if size <= 0 {
+		size = BatchSizeDefault
+	}
+	seen := make(map[string]bool, len(symbols))
+	var batch []string
+	length := 0
+	for _, s := range symbols {
+		if s = strings.ToUpper(strings.TrimSpace(s)); s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		l := len(s) + 1
+		if len(batch) == size || length+l > BatchQueryMaxLen {
+			batches = append(batches, batch)
+			batch, length = nil, 0
+		}
+		batch = append(batch, s)
+		length += l
+	}
+	if len(batch) != 0 {
+		batches = append(batches, batch)
+	}
+	return
+}
+
+// runBatches calls fn for each batch of ids and collects failed batches.
+func runBatches(ids []int, opt BatchOptions, fn func(batch []int) error) error {
+	batches := splitIds(ids, opt.Size)
+	return runParallel(len(batches), opt.Concurrency, func(i int) *BatchError {
+		if err := fn(batches[i]); err != nil {
+			return &BatchError{Ids: batches[i], Err: err}
+		}
+		return nil
+	})
+}
+
+// runSymbolBatches calls fn for each batch of symbols and collects failed batches.
+func runSymbolBatches(symbols []string, opt BatchOptions, fn func(batch []string) error) error {
+	batches := splitSymbols(symbols, opt.Size)
+	return runParallel(len(batches), opt.Concurrency, func(i int) *BatchError {
+		if err := fn(batches[i]); err != nil {
+			return &BatchError{Symbols: batches[i], Err: err}
+		}
+		return nil
+	})
+}
+
+// runParallel calls fn for each of n batches on up to workers goroutines.
+func runParallel(n, workers int, fn func(i int) *BatchError) error {
+	if workers <= 0 {
+		workers = BatchConcurrencyDefault
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs BatchErrors
+	sem := make(chan struct{}, workers)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(i); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// GetCurrencyInfoByIds acts identically to GetCurrencyInfoById, except that it accepts
+// an arbitrarily long list of ids which is split into batches.
+//
+// If some batches fail the result still holds the data of successful ones
+// and the error is of BatchErrors type.
+func (c *Client) GetCurrencyInfoByIds(
+	ctx context.Context, ids []int, opt BatchOptions) (result CurrencyInfoMap, err error) {
+	var mu sync.Mutex
+	result = CurrencyInfoMap{}
+	err = runBatches(ids, opt, func(batch []int) error {
+		var part CurrencyInfoMap
+		query := CurrencyInfoQuery{Ids: batch}
+		q, err := query.Values()
+		if err != nil {
+			return err
+		}
+		if err = c.decodeRequest(&part, ctx, ltUriCurrencyInfo, &q); err != nil {
+			return err
+		}
+		mu.Lock()
+		for k, v := range part {
+			result[k] = v
+		}
+		mu.Unlock()
+		return nil
+	})
+	return
+}
+
+// GetCurrencyQuotesLatestByIds acts identically to GetCurrencyQuotesLatestByQuery, except
+// that it accepts an arbitrarily long list of ids which is split into batches.
+//
+// If some batches fail the result still holds the data of successful ones
+// and the error is of BatchErrors type.
+func (c *Client) GetCurrencyQuotesLatestByIds(ctx context.Context, ids []int,
+	convert []string, opt BatchOptions) (result CurrencyListingMap, err error) {
+	var mu sync.Mutex
+	result = CurrencyListingMap{}
+	err = runBatches(ids, opt, func(batch []int) error {
+		var part CurrencyListingMap
+		query := CurrencyQuotesQuery{Ids: batch, Convert: convert}
+		q, err := query.Values()
+		if err != nil {
+			return err
+		}
+		if err = c.decodeRequest(&part, ctx, ltUriCurrencyQuotesLatest, &q); err != nil {
+			return err
+		}
+		mu.Lock()
+		for k, v := range part {
+			result[k] = v
+		}
+		mu.Unlock()
+		return nil
+	})
+	return
+}
+
+// GetCurrencyQuotesLatestBySymbols acts identically to GetCurrencyQuotesLatestByIds,
+// except that it accepts symbols. The result is keyed by upper-case symbol.
+func (c *Client) GetCurrencyQuotesLatestBySymbols(ctx context.Context, symbols []string,
+	convert []string, opt BatchOptions) (result CurrencyListingMap, err error) {
+	var mu sync.Mutex
+	result = CurrencyListingMap{}
+	err = runSymbolBatches(symbols, opt, func(batch []string) error {
+		var part CurrencyListingMap
+		query := CurrencyQuotesQuery{Symbols: batch, Convert: convert}
+		q, err := query.Values()
+		if err != nil {
+			return err
+		}
+		if err = c.decodeRequest(&part, ctx, ltUriCurrencyQuotesLatest, &q); err != nil {
+			return err
+		}
+		mu.Lock()
+		for k, v := range part {
+			result[strings.ToUpper(k)] = v
+		}
+		mu.Unlock()
+		return nil
+	})
+	return
+}
@@ -0,0 +1,82 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitIds(t *testing.T) {
+	ids := make([]int, 0, 250)
+	for id := 1; id <= 250; id++ {
+		ids = append(ids, id)
+	}
+	ids = append(ids, 1, 2, 3)
+	batches := splitIds(ids, 100)
+	if len(batches) != 3 || len(batches[0]) != 100 || len(batches[2]) != 50 {
+		t.Errorf("unexpected batches: %d", len(batches))
+	}
+}
+
+func TestGetCurrencyInfoByIds(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := strings.Split(r.URL.Query().Get("id"), ",")
+		if ids[0] == "3" {
+			fmt.Fprint(w, `{"status":{"error_code":400,"error_message":"Invalid value"}}`)
+			return
+		}
+		var rows []string
+		for _, id := range ids {
+			rows = append(rows, fmt.Sprintf(`"%s":{"id":%s}`, id, id))
+		}
+		fmt.Fprintf(w, `{"data":{%s},"status":{"error_code":0}}`, strings.Join(rows, ","))
+	}))
+	defer srv.Close()
+	cmc, _ := NewCustom(TestApiKey, srv.URL, ApiVersion, &http.Client{}, time.Second)
+	result, err := cmc.GetCurrencyInfoByIds(context.Background(),
+		[]int{1, 2, 3, 4, 5}, BatchOptions{Size: 2, Concurrency: 2})
+	errs, ok := err.(BatchErrors)
+	if !ok || len(errs) != 1 || errs[0].Ids[0] != 3 {
+		t.Fatalf("expected single batch error, got %v", err)
+	}
+	if len(result) != 3 || result["5"].Id != 5 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestGetCurrencyQuotesLatestBySymbols(t *testing.T) {
+	cmc, _ := NewTest()
+	calls := testServer.Calls(EndpointCurrencyQuotesLatest)
+	result, err := cmc.GetCurrencyQuotesLatestBySymbols(context.Background(),
+		[]string{"btc", "ETH", " eth ", ""}, []string{"USD"}, BatchOptions{Size: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result["BTC"].Id != 1 || result["ETH"].Id != 1027 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if n := testServer.Calls(EndpointCurrencyQuotesLatest) - calls; n != 2 {
+		t.Errorf("sent %d requests", n)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = cmc.GetCurrencyQuotesLatestBySymbols(ctx, []string{"BTC"}, nil,
+		BatchOptions{}); err == nil {
+		t.Error("expected error of canceled context")
+	}
+}
+
+func TestBatchErrorEmpty(t *testing.T) {
+	err := &BatchError{Err: fmt.Errorf("failed")}
+	if got := err.Error(); got != "empty batch: failed" {
+		t.Errorf("unexpected error %q", got)
+	}
+}
@@ -0,0 +1,190 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Cache stores successful API responses keyed by environment, domain, version,
+// endpoint and encoded query, so it may be shared by clients of different hosts
+// and sandbox data is never served to production clients.
+//
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (entry CacheEntry, ok bool)
+	Set(key string, entry CacheEntry)
+}
+
+// CacheEntry is a cached response along with the time it was received.
+type CacheEntry struct {
+	Data   json.RawMessage
+	Status ResponseStatus
+	Stored time.Time
+}
+
+// CacheTTL overrides time-to-live of cached responses per endpoint,
+// e.g. CacheTTL{EndpointCurrencyInfo: time.Hour}. Zero disables caching.
+type CacheTTL map[string]time.Duration
+
+// CacheStale is the period after expiration during which a stale response
+// is still returned while it is refreshed in the background.
+type CacheStale time.Duration
+
+// DefaultCacheTTL follows the update cadence of CoinMarketCap data.
+var DefaultCacheTTL = CacheTTL{
+	EndpointCurrencyMap:            24 * time.Hour,
+	EndpointCurrencyInfo:           24 * time.Hour,
+	EndpointCurrencyListingsLatest: time.Minute,
+	EndpointCurrencyQuotesLatest:   time.Minute,
+	EndpointGlobalQuotesLatest:     time.Minute,
+	EndpointToolsPriceConversion:   time.Minute,
+}
+
+// historicalCacheTTL is the minimum time-to-live of responses to queries for
+// a point in time, which do not change.
+const historicalCacheTTL = 24 * time.Hour
+
+// ttl returns time-to-live of responses from endpoint to the query.
+func (c *Client) ttl(endpoint string, query url.Values) time.Duration {
+	ttl, ok := c.cacheTTL[endpoint]
+	if !ok {
+		ttl = DefaultCacheTTL[endpoint]
+	}
+	if ttl > 0 && ttl < historicalCacheTTL && query.Get("time") != "" {
+		ttl = historicalCacheTTL
+	}
+	return ttl
+}
+
+// cachedFetch acts identically to fetch, except that it consults the cache first.
+func (c *Client) cachedFetch(
+	ctx context.Context, version, endpoint string, query url.Values) (Response, error) {
+	ttl := c.ttl(endpoint, query)
+	if c.cache == nil || ttl <= 0 {
+		return c.fetch(ctx, version, endpoint, query)
+	}
+	key := c.apiDomain + "/" + version + "/" + endpoint + "?" + query.Encode()
+	if c.Sandbox() {
+		key = "sandbox:" + key
+	}
+	if entry, ok := c.cache.Get(key); ok {
+		age := time.Since(entry.Stored)
+		if age < ttl {
+			return entry.response(), nil
+		}
+		if age < ttl+time.Duration(c.cacheStale) {
+			c.revalidate(key, version, endpoint, query)
+			return entry.response(), nil
+		}
+	}
+	return c.store(ctx, key, version, endpoint, query)
+}
+
+// store fetches endpoint and caches the response if it is successful.
+func (c *Client) store(
+	ctx context.Context, key, version, endpoint string, query url.Values) (Response, error) {
+	response, err := c.fetch(ctx, version, endpoint, query)
+	if err == nil && response.Status.ErrorCode == 0 {
+		c.cache.Set(key, CacheEntry{
+			Data:   response.Data,
+			Status: response.Status,
+			Stored: time.Now(),
+		})
+	}
+	return response, err
+}
+
+// revalidate refreshes the cache entry in the background, once per key at a time.
+func (c *Client) revalidate(key, version, endpoint string, query url.Values) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.revalidating[key] {
+		return
+	}
+	if c.revalidating == nil {
+		c.revalidating = make(map[string]bool)
+	}
+	c.revalidating[key] = true
+	go func() {
+		c.store(context.Background(), key, version, endpoint, query)
+		c.mu.Lock()
+		delete(c.revalidating, key)
+		c.mu.Unlock()
+	}()
+}
+
+// response returns cached response, no credits are spent for it.
+func (entry *CacheEntry) response() Response {
+	status := entry.Status
+	status.CreditCount = 0
+	return Response{Data: entry.Data, Status: status}
+}
+
+// LRUCache is an in-memory Cache which evicts least recently used entries.
+type LRUCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewLRUCache returns an instantiated LRUCache holding up to size entries.
+func NewLRUCache(size int) *LRUCache {
+	if size <= 0 {
+		size = 1
+	}
+	return &LRUCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry stored by key and marks it as recently used.
+func (lru *LRUCache) Get(key string) (entry CacheEntry, ok bool) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	el, ok := lru.items[key]
+	if !ok {
+		return
+	}
+	lru.order.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+// Set stores the entry by key evicting the least recently used one if full.
+func (lru *LRUCache) Set(key string, entry CacheEntry) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	if el, ok := lru.items[key]; ok {
+		el.Value.(*lruItem).entry = entry
+		lru.order.MoveToFront(el)
+		return
+	}
+	lru.items[key] = lru.order.PushFront(&lruItem{key: key, entry: entry})
+	if lru.order.Len() > lru.size {
+		el := lru.order.Back()
+		lru.order.Remove(el)
+		delete(lru.items, el.Value.(*lruItem).key)
+	}
+}
+
+// Len returns the number of cached entries.
+func (lru *LRUCache) Len() int {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	return lru.order.Len()
+}
@@ -0,0 +1,103 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	lru := NewLRUCache(2)
+	lru.Set("a", CacheEntry{})
+	lru.Set("b", CacheEntry{})
+	lru.Get("a")
+	lru.Set("c", CacheEntry{})
+	if _, ok := lru.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := lru.Get("a"); !ok || lru.Len() != 2 {
+		t.Error("expected a to be kept")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		fmt.Fprintf(w, `{"data":{"1":{"id":%d}},"status":{"error_code":0,"credit_count":1}}`, n)
+	}))
+	defer srv.Close()
+	cmc, _ := NewCustom(TestApiKey, srv.URL, ApiVersion, &http.Client{}, time.Second,
+		NewLRUCache(10), CacheTTL{EndpointCurrencyInfo: 50 * time.Millisecond},
+		CacheStale(time.Minute))
+	for i := 0; i < 3; i++ {
+		result, err := cmc.GetCurrencyInfoById("1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result["1"].Id != 1 {
+			t.Errorf("expected cached response, got %d", result["1"].Id)
+		}
+	}
+	time.Sleep(60 * time.Millisecond)
+	if result, _ := cmc.GetCurrencyInfoById("1"); result["1"].Id != 1 {
+		t.Errorf("expected stale response, got %d", result["1"].Id)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if result, _ := cmc.GetCurrencyInfoById("1"); result["1"].Id != 2 {
+		t.Errorf("expected revalidated response, got %d", result["1"].Id)
+	}
+}
+
+func TestSharedCacheDomains(t *testing.T) {
+	newServer := func(id int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"data":{"1":{"id":%d}},"status":{"error_code":0}}`, id)
+		}))
+	}
+	srvA, srvB := newServer(1), newServer(2)
+	defer srvA.Close()
+	defer srvB.Close()
+	cache := NewLRUCache(10)
+	a, _ := NewCustom(TestApiKey, srvA.URL, ApiVersion, &http.Client{}, time.Second, cache)
+	b, _ := NewCustom(TestApiKey, srvB.URL, ApiVersion, &http.Client{}, time.Second, cache)
+	if result, _ := a.GetCurrencyInfoById("1"); result["1"].Id != 1 {
+		t.Errorf("unexpected response of first host %+v", result)
+	}
+	if result, _ := b.GetCurrencyInfoById("1"); result["1"].Id != 2 {
+		t.Errorf("response of first host served to second %+v", result)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("cached %d responses", cache.Len())
+	}
+}
+
+func TestSharedCacheSandbox(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"1":{"id":1}},"status":{"error_code":0}}`)
+	}))
+	defer srv.Close()
+	cache := NewLRUCache(10)
+	sandbox, _ := NewCustom(TestApiKey, srv.URL, ApiVersion, &http.Client{}, time.Second,
+		cache, Sandbox)
+	prod, _ := NewCustom(TestApiKey, srv.URL, ApiVersion, &http.Client{}, time.Second, cache)
+	var status ResponseStatus
+	if _, err := sandbox.handleRequest(EndpointCurrencyInfo, &url.Values{"id": {"1"}}, &status); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prod.handleRequest(EndpointCurrencyInfo, &url.Values{"id": {"1"}}, &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Sandbox || cache.Len() != 2 {
+		t.Errorf("sandbox response served to production client, status %+v, cached %d",
+			status, cache.Len())
+	}
+}
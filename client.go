@@ -7,7 +7,7 @@ package cmcproapi
 import (
 	"errors"
 	"net/http"
-	"os"
+	"sync"
 	"time"
 )
 
@@ -17,21 +17,48 @@ const (
 	ApiRequestTimeout = 30
 )
 
+// Endpoints supported by Client, e.g. for use as CacheTTL keys.
+const (
+	EndpointCurrencyMap            = ltUriCurrencyMap
+	EndpointCurrencyInfo           = ltUriCurrencyInfo
+	EndpointCurrencyListingsLatest = ltUriCurrencyListingsLatest
+	EndpointCurrencyQuotesLatest   = ltUriCurrencyQuotesLatest
+	EndpointGlobalQuotesLatest     = ltUriGlobalQuotesLatest
+	EndpointToolsPriceConversion   = ltUriToolsPriceConversion
+	EndpointKeyInfo                = ltUriKeyInfo
+)
+
 type Client struct {
 	apiKey      string
+	keys        *KeyPool
+	source      KeySource
 	apiDomain   string
 	apiVersion  string
+	env         Environment
 	httpClient  *http.Client
 	httpTimeout time.Duration
+	limiter     *rateLimiter
+	cache       Cache
+	cacheTTL    CacheTTL
+	cacheStale  CacheStale
+	numbers     NumberMode
+	hooks       []Hook
+
+	mu           sync.Mutex
+	revalidating map[string]bool
+	flights      map[string]*flight
 }
 
-// New returns an instantiated Client struct.
+// New returns an instantiated Client struct. Without apiKey the key is read
+// from the CMC_PRO_API_KEY environment variable before every request.
 func New(apiKey string) (c *Client, err error) {
+	var source KeySource
 	if apiKey == "" {
-		apiKey = os.Getenv(ltCmcProApiKey)
+		source = EnvKey(ltCmcProApiKey)
 	}
 	c = &Client{
 		apiKey:      apiKey,
+		source:      source,
 		apiDomain:   ApiDomain,
 		apiVersion:  ApiVersion,
 		httpClient:  &http.Client{},
@@ -42,31 +69,82 @@ func New(apiKey string) (c *Client, err error) {
 
 // NewCustom returns an instantiated Client struct with custom properties.
 //
-// e.g. NewCustom(apiKey, apiDomain, apiVersion, &http.Client{}, time.Minute)
+// e.g. NewCustom(apiKey, apiDomain, apiVersion, &http.Client{}, time.Minute, RateLimit(30))
+//
+// A *KeyPool or a KeySource takes the place of apiKey, the strings are then
+// the domain and version. Omitted or empty domain, version, HTTP client and
+// timeout take the defaults of the Environment, Production by default.
 func NewCustom(args ...interface{}) (c *Client, err error) {
 	if args == nil {
 		err = errors.New(ltMsgEmptyArgs)
 		return
 	}
 	c = &Client{}
+	var strs []string
 	for _, arg := range args {
 		switch val := arg.(type) {
 		case string:
-			if c.apiKey == "" {
-				c.apiKey = val
-			} else if c.apiDomain == "" {
-				c.apiDomain = val
-			} else if c.apiVersion == "" {
-				c.apiVersion = val
-			}
+			strs = append(strs, val)
+		case *KeyPool:
+			c.keys = val
+		case KeySource:
+			c.source = val
 		case *http.Client:
 			c.httpClient = val
 		case time.Duration:
 			c.httpTimeout = val
+		case RateLimit:
+			c.limiter = newRateLimiter(val)
+		case Cache:
+			c.cache = val
+		case CacheTTL:
+			c.cacheTTL = val
+		case CacheStale:
+			c.cacheStale = val
+		case NumberMode:
+			c.numbers = val
+		case Environment:
+			c.env = val
+		case Hook:
+			c.hooks = append(c.hooks, val)
+		case Logger:
+			c.hooks = append(c.hooks, LogHook(val))
 		default:
 			err = errors.New(ltMsgUnsupArgType)
 			return
 		}
 	}
+	if c.keys == nil && c.source == nil && len(strs) != 0 {
+		c.apiKey, strs = strs[0], strs[1:]
+	}
+	if len(strs) > 0 {
+		c.apiDomain = strs[0]
+	}
+	if len(strs) > 1 {
+		c.apiVersion = strs[1]
+	}
+	c.defaults()
 	return
 }
+
+// defaults sets properties omitted in NewCustom.
+func (c *Client) defaults() {
+	if c.apiDomain == "" {
+		c.apiDomain = ApiDomain
+		if c.env == Sandbox {
+			c.apiDomain = SandboxApiDomain
+		}
+	}
+	if c.apiKey == "" && c.keys == nil && c.source == nil && c.env == Sandbox {
+		c.apiKey = SandboxApiKey
+	}
+	if c.apiVersion == "" {
+		c.apiVersion = ApiVersion
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{}
+	}
+	if c.httpTimeout == 0 {
+		c.httpTimeout = ApiRequestTimeout * time.Second
+	}
+}
@@ -6,21 +6,34 @@ package cmcproapi
 
 import (
 	"net/http"
+	"os"
 	"testing"
 	"time"
+
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
 )
 
 const (
-	TestApiKey     = "42d49444-36c2-47f2-97e5-6b1322657e3d"
-	TestApiDomain  = "https://sandbox-api.coinmarketcap.com"
+	TestApiKey     = SandboxApiKey
+	TestApiDomain  = SandboxApiDomain
 	TestApiVersion = "v1"
 )
 
+// testServer is a fake API shared by all tests of the package.
+var testServer *cmcproapitest.Server
+
+func TestMain(m *testing.M) {
+	testServer = cmcproapitest.NewServer()
+	code := m.Run()
+	testServer.Close()
+	os.Exit(code)
+}
+
 // NewTest returns an instantiated Client struct for testing purposes.
 func NewTest() (c *Client, err error) {
 	c = &Client{
-		apiKey:      TestApiKey,
-		apiDomain:   TestApiDomain,
+		apiKey:      cmcproapitest.APIKey,
+		apiDomain:   testServer.URL,
 		apiVersion:  TestApiVersion,
 		httpClient:  &http.Client{},
 		httpTimeout: ApiRequestTimeout * time.Second,
@@ -0,0 +1,107 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapitest
+
+// Timestamp is the last_updated value of all fixture data.
+const Timestamp = "2019-08-30T18:51:28.000Z"
+
+// Platform is a fixture of the platform a token is issued on.
+type Platform struct {
+	Id           int    `json:"id"`
+	Name         string `json:"name"`
+	Symbol       string `json:"symbol"`
+	Slug         string `json:"slug"`
+	TokenAddress string `json:"token_address"`
+}
+
+// Currency is a fixture of a cryptocurrency served by Server.
+type Currency struct {
+	Id                int
+	Name              string
+	Symbol            string
+	Slug              string
+	Rank              int
+	IsActive          int
+	Status            string
+	Category          string
+	PriceUSD          float64
+	Volume24hUSD      float64
+	PercentChange1h   float64
+	PercentChange24h  float64
+	PercentChange7d   float64
+	CirculatingSupply float64
+	TotalSupply       float64
+	MaxSupply         float64
+	DateAdded         string
+	Tags              []string
+	Platform          *Platform
+}
+
+// Fiat is a fixture of a fiat currency usable as convert option.
+type Fiat struct {
+	Id       int
+	Symbol   string
+	PriceUSD float64
+}
+
+// Currencies are served by a new Server sorted by rank.
+var Currencies = []Currency{
+	{
+		Id: 1, Name: "Bitcoin", Symbol: "BTC", Slug: "bitcoin", Rank: 1,
+		IsActive: 1, Status: "active", Category: "coin",
+		PriceUSD: 9558.55163723, Volume24hUSD: 13728947008.2722,
+		PercentChange1h: -0.127291, PercentChange24h: 0.328918, PercentChange7d: -8.00576,
+		CirculatingSupply: 17906012, TotalSupply: 17906012, MaxSupply: 21000000,
+		DateAdded: "2013-04-28T00:00:00.000Z", Tags: []string{"mineable"},
+	},
+	{
+		Id: 1027, Name: "Ethereum", Symbol: "ETH", Slug: "ethereum", Rank: 2,
+		IsActive: 1, Status: "active", Category: "coin",
+		PriceUSD: 168.306607664, Volume24hUSD: 6013571706.59623,
+		PercentChange1h: -0.242813, PercentChange24h: -0.826099, PercentChange7d: -12.2163,
+		CirculatingSupply: 107537529.374, TotalSupply: 107537529.374,
+		DateAdded: "2015-08-07T00:00:00.000Z", Tags: []string{"mineable"},
+	},
+	{
+		Id: 52, Name: "XRP", Symbol: "XRP", Slug: "ripple", Rank: 3,
+		IsActive: 1, Status: "active", Category: "coin",
+		PriceUSD: 0.256418217754, Volume24hUSD: 1028863232.94477,
+		PercentChange1h: -0.0845513, PercentChange24h: -0.431627, PercentChange7d: -6.61925,
+		CirculatingSupply: 42932866967, TotalSupply: 99991366246, MaxSupply: 100000000000,
+		DateAdded: "2013-08-04T00:00:00.000Z",
+	},
+	{
+		Id: 2, Name: "Litecoin", Symbol: "LTC", Slug: "litecoin", Rank: 4,
+		IsActive: 1, Status: "active", Category: "coin",
+		PriceUSD: 64.2290958485, Volume24hUSD: 2334419563.98785,
+		PercentChange1h: -0.386036, PercentChange24h: 0.195406, PercentChange7d: -11.5293,
+		CirculatingSupply: 63043580.5294, TotalSupply: 63043580.5294, MaxSupply: 84000000,
+		DateAdded: "2013-04-28T00:00:00.000Z", Tags: []string{"mineable"},
+	},
+	{
+		Id: 825, Name: "Tether", Symbol: "USDT", Slug: "tether", Rank: 5,
+		IsActive: 1, Status: "active", Category: "token",
+		PriceUSD: 1.00131416009, Volume24hUSD: 16478420093.3714,
+		PercentChange1h: 0.0302341, PercentChange24h: 0.0745591, PercentChange7d: 0.0851917,
+		CirculatingSupply: 4007804937.21, TotalSupply: 4095057493.39,
+		DateAdded: "2015-02-25T00:00:00.000Z",
+		Platform: &Platform{
+			Id: 1027, Name: "Ethereum", Symbol: "ETH", Slug: "ethereum",
+			TokenAddress: "0xdAC17F958D2ee523a2206206994597C13D831ec7",
+		},
+	},
+	{
+		Id: 3602, Name: "Bitcoin Free Cash", Symbol: "BTC", Slug: "bitcoin-free-cash", Rank: 0,
+		IsActive: 0, Status: "inactive", Category: "coin",
+		DateAdded: "2018-12-05T00:00:00.000Z",
+	},
+}
+
+// Fiats are accepted as convert and convert_id options along with Currencies.
+var Fiats = []Fiat{
+	{Id: 2781, Symbol: "USD", PriceUSD: 1},
+	{Id: 2790, Symbol: "EUR", PriceUSD: 1.0988},
+	{Id: 2791, Symbol: "GBP", PriceUSD: 1.2174},
+}
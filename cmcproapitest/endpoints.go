@@ -0,0 +1,437 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapitest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var handlers = map[string]handler{
+	"cryptocurrency/map":             (*Server).currencyMap,
+	"cryptocurrency/info":            (*Server).currencyInfo,
+	"cryptocurrency/listings/latest": (*Server).currencyListingsLatest,
+	"cryptocurrency/quotes/latest":   (*Server).currencyQuotesLatest,
+	"global-metrics/quotes/latest":   (*Server).globalQuotesLatest,
+	"tools/price-conversion":         (*Server).priceConversion,
+	"key/info":                       (*Server).keyInfo,
+}
+
+// convert is a resolved convert option along with the key of the quote map.
+type convert struct {
+	key      string
+	priceUSD float64
+}
+
+func (s *Server) currencyMap(r *http.Request) (interface{}, int, error) {
+	currencies, _ := s.data()
+	q := r.URL.Query()
+	start, limit, err := page(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	statuses := map[string]bool{"active": true}
+	if v := q.Get("listing_status"); v != "" {
+		statuses = map[string]bool{}
+		for _, st := range strings.Split(v, ",") {
+			statuses[st] = true
+		}
+	}
+	symbols := map[string]bool{}
+	if v := q.Get("symbol"); v != "" {
+		for _, sym := range strings.Split(v, ",") {
+			symbols[strings.ToUpper(sym)] = true
+		}
+	}
+	var list []Currency
+	for _, c := range currencies {
+		if statuses[c.Status] && (len(symbols) == 0 || symbols[c.Symbol]) {
+			list = append(list, c)
+		}
+	}
+	rows := []map[string]interface{}{}
+	for _, c := range paginate(list, start, limit) {
+		rows = append(rows, map[string]interface{}{
+			"id":                    c.Id,
+			"name":                  c.Name,
+			"symbol":                c.Symbol,
+			"slug":                  c.Slug,
+			"rank":                  c.Rank,
+			"is_active":             c.IsActive,
+			"first_historical_data": c.DateAdded,
+			"last_historical_data":  Timestamp,
+			"platform":              c.Platform,
+		})
+	}
+	return rows, 1, nil
+}
+
+func (s *Server) currencyInfo(r *http.Request) (interface{}, int, error) {
+	list, keys, err := s.identify(r.URL.Query())
+	if err != nil {
+		return nil, 0, err
+	}
+	data := map[string]interface{}{}
+	for i, c := range list {
+		contracts := []interface{}{}
+		if c.Platform != nil {
+			contracts = append(contracts, map[string]interface{}{
+				"contract_address": c.Platform.TokenAddress,
+				"platform": map[string]interface{}{
+					"name": c.Platform.Name,
+					"coin": map[string]string{
+						"id":     strconv.Itoa(c.Platform.Id),
+						"name":   c.Platform.Name,
+						"symbol": c.Platform.Symbol,
+						"slug":   c.Platform.Slug,
+					},
+				},
+			})
+		}
+		data[keys[i]] = map[string]interface{}{
+			"contract_address": contracts,
+			"id":               c.Id,
+			"name":             c.Name,
+			"symbol":           c.Symbol,
+			"category":         c.Category,
+			"slug":             c.Slug,
+			"logo":             fmt.Sprintf("https://s2.coinmarketcap.com/static/img/coins/64x64/%d.png", c.Id),
+			"description":      c.Name + " (" + c.Symbol + ") is a cryptocurrency.",
+			"date_added":       c.DateAdded,
+			"notice":           "",
+			"tags":             c.Tags,
+			"platform":         c.Platform,
+			"urls": map[string][]string{
+				"website": {"https://" + c.Slug + ".org/"},
+			},
+		}
+	}
+	return data, credits(len(list), 100), nil
+}
+
+func (s *Server) currencyListingsLatest(r *http.Request) (interface{}, int, error) {
+	currencies, _ := s.data()
+	q := r.URL.Query()
+	start, limit, err := page(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	converts, err := s.converts(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	var active []Currency
+	for _, c := range currencies {
+		if c.IsActive == 1 {
+			active = append(active, c)
+		}
+	}
+	rows := []map[string]interface{}{}
+	list := paginate(active, start, limit)
+	for _, c := range list {
+		rows = append(rows, listing(c, converts))
+	}
+	return rows, credits(len(list), 200) + len(converts) - 1, nil
+}
+
+func (s *Server) currencyQuotesLatest(r *http.Request) (interface{}, int, error) {
+	q := r.URL.Query()
+	list, keys, err := s.identify(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	converts, err := s.converts(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	data := map[string]interface{}{}
+	for i, c := range list {
+		data[keys[i]] = listing(c, converts)
+	}
+	return data, credits(len(list), 100) + len(converts) - 1, nil
+}
+
+func (s *Server) globalQuotesLatest(r *http.Request) (interface{}, int, error) {
+	currencies, _ := s.data()
+	converts, err := s.converts(r.URL.Query())
+	if err != nil {
+		return nil, 0, err
+	}
+	var total, volume, btc, eth float64
+	active := 0
+	for _, c := range currencies {
+		if c.IsActive != 1 {
+			continue
+		}
+		active++
+		mcap := c.PriceUSD * c.CirculatingSupply
+		total += mcap
+		volume += c.Volume24hUSD
+		switch c.Symbol {
+		case "BTC":
+			btc = mcap
+		case "ETH":
+			eth = mcap
+		}
+	}
+	quotes := map[string]interface{}{}
+	for _, cv := range converts {
+		quotes[cv.key] = map[string]interface{}{
+			"total_market_cap":            total / cv.priceUSD,
+			"total_volume_24h":            volume / cv.priceUSD,
+			"total_volume_24h_reported":   volume / cv.priceUSD,
+			"altcoin_volume_24h":          (volume - currencies[0].Volume24hUSD) / cv.priceUSD,
+			"altcoin_volume_24h_reported": (volume - currencies[0].Volume24hUSD) / cv.priceUSD,
+			"altcoin_market_cap":          (total - btc) / cv.priceUSD,
+			"last_updated":                Timestamp,
+		}
+	}
+	return map[string]interface{}{
+		"btc_dominance":           btc / total * 100,
+		"eth_dominance":           eth / total * 100,
+		"active_cryptocurrencies": active,
+		"total_cryptocurrencies":  len(currencies),
+		"active_market_pairs":     active * 100,
+		"active_exchanges":        10,
+		"total_exchanges":         20,
+		"last_updated":            Timestamp,
+		"quote":                   quotes,
+	}, len(converts), nil
+}
+
+func (s *Server) priceConversion(r *http.Request) (interface{}, int, error) {
+	q := r.URL.Query()
+	amount, err := strconv.ParseFloat(q.Get("amount"), 64)
+	if err != nil || amount <= 0 {
+		return nil, 0, requestError(`"amount" must be a positive number`)
+	}
+	if strings.Contains(q.Get("id")+q.Get("symbol"), ",") {
+		return nil, 0, requestError(`only one "id" or "symbol" is allowed`)
+	}
+	list, _, err := s.identify(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	converts, err := s.converts(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	// historical conversions use the same prices as of the requested time
+	updated := Timestamp
+	if v := q.Get("time"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			sec, e := strconv.ParseInt(v, 10, 64)
+			if e != nil {
+				return nil, 0, requestError(`"time" must be a valid ISO 8601 timestamp or unix time value`)
+			}
+			t = time.Unix(sec, 0)
+		}
+		updated = t.UTC().Format("2006-01-02T15:04:05.000Z")
+	}
+	c := list[0]
+	quotes := map[string]interface{}{}
+	for _, cv := range converts {
+		quotes[cv.key] = map[string]interface{}{
+			"price":        amount * c.PriceUSD / cv.priceUSD,
+			"last_updated": updated,
+		}
+	}
+	return map[string]interface{}{
+		"id":           c.Id,
+		"symbol":       c.Symbol,
+		"name":         c.Name,
+		"amount":       amount,
+		"last_updated": updated,
+		"quote":        quotes,
+	}, len(converts), nil
+}
+
+func (s *Server) keyInfo(r *http.Request) (interface{}, int, error) {
+	s.mu.Lock()
+	used, requests := s.credits, 0
+	for _, n := range s.calls {
+		requests += n
+	}
+	s.mu.Unlock()
+	return map[string]interface{}{
+		"plan": map[string]interface{}{
+			"credit_limit_daily":                   333,
+			"credit_limit_daily_reset":             "In 5 hours, 8 minutes",
+			"credit_limit_daily_reset_timestamp":   "2019-08-31T00:00:00.000Z",
+			"credit_limit_monthly":                 10000,
+			"credit_limit_monthly_reset":           "In 1 day, 5 hours",
+			"credit_limit_monthly_reset_timestamp": "2019-09-01T00:00:00.000Z",
+			"rate_limit_minute":                    30,
+		},
+		"usage": map[string]interface{}{
+			"current_minute": map[string]int{"requests_made": requests, "requests_left": 30 - requests},
+			"current_day":    map[string]int{"credits_used": used, "credits_left": 333 - used},
+			"current_month":  map[string]int{"credits_used": used, "credits_left": 10000 - used},
+		},
+	}, 0, nil
+}
+
+// identify returns currencies matching exactly one of id, symbol or slug
+// parameters along with the keys the API uses for them in the response.
+func (s *Server) identify(q url.Values) (list []Currency, keys []string, err error) {
+	currencies, _ := s.data()
+	var values []string
+	var match func(c Currency, v string) bool
+	switch {
+	case q.Get("id") != "":
+		values = strings.Split(q.Get("id"), ",")
+		match = func(c Currency, v string) bool { return strconv.Itoa(c.Id) == v }
+	case q.Get("symbol") != "":
+		values = strings.Split(q.Get("symbol"), ",")
+		match = func(c Currency, v string) bool { return strings.EqualFold(c.Symbol, v) }
+	case q.Get("slug") != "":
+		values = strings.Split(q.Get("slug"), ",")
+		match = func(c Currency, v string) bool { return c.Slug == strings.ToLower(v) }
+	case q.Get("address") != "":
+		values = []string{q.Get("address")}
+		match = func(c Currency, v string) bool {
+			return c.Platform != nil && strings.EqualFold(c.Platform.TokenAddress, v)
+		}
+	default:
+		return nil, nil, requestError(`"value" must contain at least one of [id, symbol, slug, address]`)
+	}
+	for _, v := range values {
+		found := false
+		for _, c := range currencies {
+			if match(c, v) {
+				list = append(list, c)
+				if q.Get("symbol") == "" {
+					keys = append(keys, strconv.Itoa(c.Id))
+				} else {
+					keys = append(keys, c.Symbol)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, requestError(fmt.Sprintf("Invalid value for \"id\": \"%s\"", v))
+		}
+	}
+	return
+}
+
+// converts resolves convert or convert_id parameters, USD is used by default.
+func (s *Server) converts(q url.Values) (list []convert, err error) {
+	currencies, fiats := s.data()
+	byId := q.Get("convert_id") != ""
+	values := []string{"USD"}
+	if v := q.Get("convert"); v != "" {
+		if byId {
+			return nil, requestError(`"convert" and "convert_id" are mutually exclusive`)
+		}
+		values = strings.Split(v, ",")
+	} else if byId {
+		values = strings.Split(q.Get("convert_id"), ",")
+	}
+	for _, v := range values {
+		found := false
+		for _, f := range fiats {
+			if (byId && strconv.Itoa(f.Id) == v) || (!byId && strings.EqualFold(f.Symbol, v)) {
+				list = append(list, convert{key: key(byId, f.Id, f.Symbol), priceUSD: f.PriceUSD})
+				found = true
+				break
+			}
+		}
+		for _, c := range currencies {
+			if found {
+				break
+			}
+			if c.IsActive == 1 &&
+				((byId && strconv.Itoa(c.Id) == v) || (!byId && strings.EqualFold(c.Symbol, v))) {
+				list = append(list, convert{key: key(byId, c.Id, c.Symbol), priceUSD: c.PriceUSD})
+				found = true
+			}
+		}
+		if !found {
+			return nil, requestError(fmt.Sprintf("Invalid value for \"convert\": \"%s\"", v))
+		}
+	}
+	return
+}
+
+func key(byId bool, id int, symbol string) string {
+	if byId {
+		return strconv.Itoa(id)
+	}
+	return symbol
+}
+
+// listing returns market data of c with quotes in converts.
+func listing(c Currency, converts []convert) map[string]interface{} {
+	quotes := map[string]interface{}{}
+	for _, cv := range converts {
+		quotes[cv.key] = map[string]interface{}{
+			"price":              c.PriceUSD / cv.priceUSD,
+			"volume_24h":         c.Volume24hUSD / cv.priceUSD,
+			"percent_change_1h":  c.PercentChange1h,
+			"percent_change_24h": c.PercentChange24h,
+			"percent_change_7d":  c.PercentChange7d,
+			"market_cap":         c.PriceUSD * c.CirculatingSupply / cv.priceUSD,
+			"last_updated":       Timestamp,
+		}
+	}
+	return map[string]interface{}{
+		"id":                 c.Id,
+		"name":               c.Name,
+		"symbol":             c.Symbol,
+		"slug":               c.Slug,
+		"cmc_rank":           c.Rank,
+		"num_market_pairs":   100,
+		"circulating_supply": c.CirculatingSupply,
+		"total_supply":       c.TotalSupply,
+		"max_supply":         c.MaxSupply,
+		"last_updated":       Timestamp,
+		"date_added":         c.DateAdded,
+		"tags":               c.Tags,
+		"platform":           c.Platform,
+		"quote":              quotes,
+	}
+}
+
+// page parses and validates start and limit parameters.
+func page(q url.Values) (start, limit int, err error) {
+	start, limit = 1, 100
+	if v := q.Get("start"); v != "" {
+		if start, err = strconv.Atoi(v); err != nil || start < 1 {
+			return 0, 0, requestError(`"start" must be larger than or equal to 1`)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > 5000 {
+			return 0, 0, requestError(`"limit" must be between 1 and 5000`)
+		}
+	}
+	return
+}
+
+func paginate(list []Currency, start, limit int) []Currency {
+	if start > len(list) {
+		return nil
+	}
+	list = list[start-1:]
+	if limit < len(list) {
+		list = list[:limit]
+	}
+	return list
+}
+
+// credits returns the number of credits charged for n data points.
+func credits(n, per int) int {
+	if n == 0 {
+		return 1
+	}
+	return (n + per - 1) / per
+}
@@ -0,0 +1,185 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapitest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Mode of Recorder.
+type Mode int
+
+const (
+	// ModeReplay serves recorded responses and fails on unmatched requests.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests upstream and stores responses to golden files.
+	ModeRecord
+)
+
+const (
+	apiKeyHeader = "X-CMC_PRO_API_KEY"
+	apiKeyParam  = "CMC_PRO_API_KEY"
+	redacted     = "REDACTED"
+)
+
+// Recorder is an http.RoundTripper which records responses to golden files in Dir
+// and replays them deterministically, e.g.
+//
+//	rec := cmcproapitest.NewRecorder("testdata", cmcproapitest.ModeReplay)
+//	cmc, _ := cmcproapi.NewCustom(apiKey, cmcproapi.ApiDomain, cmcproapi.ApiVersion,
+//		&http.Client{Transport: rec}, time.Minute)
+//
+// Requests are matched by method, path and sorted query without the API key.
+// The API key header is never stored. With Redact set, the API key is also
+// scrubbed from recorded response headers and bodies.
+type Recorder struct {
+	Mode      Mode
+	Dir       string
+	Redact    bool
+	Transport http.RoundTripper
+}
+
+// Fixture is the content of a golden file.
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+// FixtureRequest identifies a recorded request.
+type FixtureRequest struct {
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+	Query    string `json:"query"`
+}
+
+// FixtureResponse is a recorded response.
+type FixtureResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// NewRecorder returns a Recorder storing golden files in dir.
+func NewRecorder(dir string, mode Mode) *Recorder {
+	return &Recorder{Mode: mode, Dir: dir, Redact: true}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rec *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	fr := fixtureRequest(req)
+	path := filepath.Join(rec.Dir, fr.filename())
+	if rec.Mode == ModeReplay {
+		return rec.replay(req, fr, path)
+	}
+	return rec.record(req, fr, path)
+}
+
+func (rec *Recorder) replay(
+	req *http.Request, fr FixtureRequest, path string) (*http.Response, error) {
+	var f Fixture
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("cmcproapitest: no recorded response for %s %s?%s",
+			fr.Method, fr.Endpoint, fr.Query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Request != fr {
+		return nil, fmt.Errorf("cmcproapitest: recorded request mismatch in %s", path)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Response.StatusCode, http.StatusText(f.Response.StatusCode)),
+		StatusCode:    f.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Response.Header,
+		Body:          ioutil.NopCloser(strings.NewReader(f.Response.Body)),
+		ContentLength: int64(len(f.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (rec *Recorder) record(
+	req *http.Request, fr FixtureRequest, path string) (*http.Response, error) {
+	transport := rec.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	f := Fixture{
+		Request: fr,
+		Response: FixtureResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(body),
+		},
+	}
+	if rec.Redact {
+		f.Response.redact(req.Header.Get(apiKeyHeader), req.URL.Query().Get(apiKeyParam))
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(rec.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(path, data, 0644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// fixtureRequest returns request identity without the API key.
+func fixtureRequest(req *http.Request) FixtureRequest {
+	q := req.URL.Query()
+	q.Del(apiKeyParam)
+	return FixtureRequest{Method: req.Method, Endpoint: req.URL.Path, Query: q.Encode()}
+}
+
+// filename returns the golden file name, e.g. v1_cryptocurrency_map-0a1b2c3d.json.
+func (fr FixtureRequest) filename() string {
+	sum := sha1.Sum([]byte(fr.Method + " " + fr.Endpoint + "?" + fr.Query))
+	name := strings.Replace(strings.Trim(fr.Endpoint, "/"), "/", "_", -1)
+	return name + "-" + hex.EncodeToString(sum[:4]) + ".json"
+}
+
+// redact scrubs API keys from the response.
+func (fr *FixtureResponse) redact(keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		fr.Body = strings.Replace(fr.Body, key, redacted, -1)
+		for name, values := range fr.Header {
+			for i, v := range values {
+				fr.Header[name][i] = strings.Replace(v, key, redacted, -1)
+			}
+		}
+	}
+}
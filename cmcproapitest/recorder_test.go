@@ -0,0 +1,56 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapitest_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cmcproapitest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	srv := cmcproapitest.NewServer()
+	rec := cmcproapitest.NewRecorder(dir, cmcproapitest.ModeRecord)
+	cmc, _ := cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, "v1",
+		&http.Client{Transport: rec}, time.Second)
+	recorded, err := cmc.GetCurrencyInfoBySymbol("BTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("expected one golden file, got %d", len(files))
+	}
+	data, _ := ioutil.ReadFile(files[0])
+	if strings.Contains(string(data), cmcproapitest.APIKey) {
+		t.Error("golden file contains API key")
+	}
+
+	rec.Mode = cmcproapitest.ModeReplay
+	replayed, err := cmc.GetCurrencyInfoBySymbol("BTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed["BTC"].Id != recorded["BTC"].Id {
+		t.Errorf("replayed %+v, recorded %+v", replayed["BTC"], recorded["BTC"])
+	}
+	if _, err = cmc.GetCurrencyInfoBySymbol("ETH"); err == nil {
+		t.Error("expected unmatched request error")
+	}
+}
@@ -0,0 +1,293 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+// Package cmcproapitest provides a fake CoinMarketCap API server for hermetic tests.
+//
+//	srv := cmcproapitest.NewServer()
+//	defer srv.Close()
+//	cmc, _ := cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, "v1",
+//		&http.Client{}, time.Minute)
+package cmcproapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKey is the key accepted by Server, more keys may be added with AddKey.
+const APIKey = "b54bcf4d-1bca-4e8e-9a24-22ff2c3d462c"
+
+// Error codes of the status object returned by the API.
+const (
+	ErrorCodeBadRequest          = 400
+	ErrorCodeApiKeyInvalid       = 1001
+	ErrorCodeApiKeyMissing       = 1002
+	ErrorCodePlanRequiresPayment = 1003
+	ErrorCodePlanPaymentExpired  = 1004
+	ErrorCodeApiKeyRequired      = 1005
+	ErrorCodePlanNotAuthorized   = 1006
+	ErrorCodeApiKeyDisabled      = 1007
+	ErrorCodeMinuteRateLimit     = 1008
+	ErrorCodeDailyRateLimit      = 1009
+	ErrorCodeMonthlyRateLimit    = 1010
+	ErrorCodeIpRateLimit         = 1011
+	ErrorCodeInternal            = 500
+)
+
+var errorMessages = map[int]string{
+	ErrorCodeApiKeyInvalid:       "This API Key is invalid.",
+	ErrorCodeApiKeyMissing:       "API key missing.",
+	ErrorCodePlanRequiresPayment: "Your API Key must be activated. Please go to pro.coinmarketcap.com/account/plan.",
+	ErrorCodePlanPaymentExpired:  "Your API Key's subscription plan has expired.",
+	ErrorCodeApiKeyRequired:      "An API Key is required for this call.",
+	ErrorCodePlanNotAuthorized:   "Your API Key subscription plan doesn't support this endpoint.",
+	ErrorCodeApiKeyDisabled:      "This API Key has been disabled. Please contact support.",
+	ErrorCodeMinuteRateLimit:     "You've exceeded your API Key's HTTP request rate limit. Rate limits reset every minute.",
+	ErrorCodeDailyRateLimit:      "You've exceeded your API Key's daily rate limit.",
+	ErrorCodeMonthlyRateLimit:    "You've exceeded your API Key's monthly credit limit.",
+	ErrorCodeIpRateLimit:         "You've hit an IP rate limit.",
+	ErrorCodeInternal:            "An internal server error occurred",
+}
+
+// HTTPStatus returns HTTP status code the API responds with for error code.
+func HTTPStatus(code int) int {
+	switch code {
+	case 0:
+		return http.StatusOK
+	case ErrorCodeApiKeyInvalid, ErrorCodeApiKeyMissing, ErrorCodeApiKeyRequired:
+		return http.StatusUnauthorized
+	case ErrorCodePlanRequiresPayment, ErrorCodePlanPaymentExpired:
+		return http.StatusPaymentRequired
+	case ErrorCodePlanNotAuthorized, ErrorCodeApiKeyDisabled:
+		return http.StatusForbidden
+	case ErrorCodeMinuteRateLimit, ErrorCodeDailyRateLimit,
+		ErrorCodeMonthlyRateLimit, ErrorCodeIpRateLimit:
+		return http.StatusTooManyRequests
+	case ErrorCodeBadRequest:
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// Fault is injected into responses of an endpoint.
+//
+// ErrorCode sets the status object and the matching HTTP status, which may be
+// overridden by HTTPStatus. Body replaces the whole response, e.g. to serve
+// malformed JSON. Count limits the number of affected requests, zero means all.
+// Key limits the fault to requests with the API key, empty means any key.
+type Fault struct {
+	ErrorCode  int
+	HTTPStatus int
+	Body       string
+	Count      int
+	Key        string
+}
+
+// Server is a fake CoinMarketCap API backed by httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	latency    time.Duration
+	faults     map[string]*Fault
+	keys       map[string]bool
+	calls      map[string]int
+	credits    int
+	currencies []Currency
+	fiats      []Fiat
+}
+
+type status struct {
+	Timestamp    string `json:"timestamp"`
+	ErrorCode    int    `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+	Elapsed      int    `json:"elapsed"`
+	CreditCount  int    `json:"credit_count"`
+}
+
+type envelope struct {
+	Data   interface{} `json:"data,omitempty"`
+	Status status      `json:"status"`
+}
+
+// requestError is returned by handlers for invalid query parameters.
+type requestError string
+
+func (e requestError) Error() string {
+	return string(e)
+}
+
+type handler func(s *Server, r *http.Request) (data interface{}, credits int, err error)
+
+// NewServer starts and returns a Server serving Currencies and Fiats.
+func NewServer() *Server {
+	s := &Server{
+		faults:     make(map[string]*Fault),
+		keys:       map[string]bool{APIKey: true},
+		calls:      make(map[string]int),
+		currencies: append([]Currency(nil), Currencies...),
+		fiats:      append([]Fiat(nil), Fiats...),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	s.latency = d
+	s.mu.Unlock()
+}
+
+// SetFault injects f into responses of endpoint, e.g. "cryptocurrency/map".
+// Empty endpoint affects all endpoints.
+func (s *Server) SetFault(endpoint string, f Fault) {
+	s.mu.Lock()
+	s.faults[endpoint] = &f
+	s.mu.Unlock()
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	s.faults = make(map[string]*Fault)
+	s.mu.Unlock()
+}
+
+// AddKey makes Server accept keys besides APIKey.
+func (s *Server) AddKey(keys ...string) {
+	s.mu.Lock()
+	for _, key := range keys {
+		s.keys[key] = true
+	}
+	s.mu.Unlock()
+}
+
+// SetPrice changes USD price of the currency with id.
+func (s *Server) SetPrice(id int, priceUSD float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.currencies {
+		if s.currencies[i].Id == id {
+			s.currencies[i].PriceUSD = priceUSD
+		}
+	}
+}
+
+// data returns a copy of currencies and fiats served.
+func (s *Server) data() ([]Currency, []Fiat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Currency(nil), s.currencies...), s.fiats
+}
+
+// Calls returns the number of requests received by endpoint.
+func (s *Server) Calls(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[endpoint]
+}
+
+// Credits returns the number of credits charged by all successful requests.
+func (s *Server) Credits() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.credits
+}
+
+// fault returns the fault to be injected into the response of endpoint
+// requested with key, if any.
+func (s *Server) fault(endpoint, key string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[endpoint]++
+	for _, name := range []string{endpoint, ""} {
+		f, ok := s.faults[name]
+		if !ok || (f.Key != "" && f.Key != key) {
+			continue
+		}
+		if f.Count > 0 {
+			if f.Count--; f.Count == 0 {
+				delete(s.faults, name)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	started := time.Now()
+	s.mu.Lock()
+	latency := s.latency
+	s.mu.Unlock()
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	// strip version, e.g. /v1/cryptocurrency/map
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	endpoint := path
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		endpoint = path[i+1:]
+	}
+	var resp envelope
+	resp.Status.Timestamp = started.UTC().Format(time.RFC3339Nano)
+	code, httpStatus := 0, 0
+	key := r.Header.Get("X-CMC_PRO_API_KEY")
+	s.mu.Lock()
+	valid := s.keys[key]
+	s.mu.Unlock()
+	switch f := s.fault(endpoint, key); {
+	case f != nil && f.Body != "":
+		if httpStatus = f.HTTPStatus; httpStatus == 0 {
+			httpStatus = HTTPStatus(f.ErrorCode)
+		}
+		w.WriteHeader(httpStatus)
+		fmt.Fprint(w, f.Body)
+		return
+	case f != nil:
+		code, httpStatus = f.ErrorCode, f.HTTPStatus
+		resp.Status.ErrorMessage = errorMessages[code]
+	case key == "":
+		code = ErrorCodeApiKeyMissing
+		resp.Status.ErrorMessage = errorMessages[code]
+	case !valid:
+		code = ErrorCodeApiKeyInvalid
+		resp.Status.ErrorMessage = errorMessages[code]
+	default:
+		h, ok := handlers[endpoint]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data, credits, err := h(s, r)
+		if err != nil {
+			code = ErrorCodeBadRequest
+			resp.Status.ErrorMessage = err.Error()
+			break
+		}
+		resp.Data = data
+		resp.Status.CreditCount = credits
+		s.mu.Lock()
+		s.credits += credits
+		s.mu.Unlock()
+	}
+	if httpStatus == 0 {
+		httpStatus = HTTPStatus(code)
+	}
+	resp.Status.ErrorCode = code
+	resp.Status.Elapsed = int(time.Since(started) / time.Millisecond)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(resp)
+}
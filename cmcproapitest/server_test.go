@@ -0,0 +1,57 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapitest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+func TestServerQuotes(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	cmc, _ := cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, "v1",
+		&http.Client{}, time.Second)
+	result, err := cmc.GetCurrencyQuotesLatestByQuery(context.Background(),
+		cmcproapi.CurrencyQuotesQuery{Ids: []int{1, 1027}, Convert: []string{"USD", "EUR"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	btc := result["1"]
+	if btc.Symbol != "BTC" || (*btc.Quote)["EUR"].Price >= (*btc.Quote)["USD"].Price {
+		t.Errorf("unexpected quote: %+v", btc)
+	}
+	if srv.Calls("cryptocurrency/quotes/latest") != 1 || srv.Credits() != 2 {
+		t.Errorf("unexpected calls %d or credits %d",
+			srv.Calls("cryptocurrency/quotes/latest"), srv.Credits())
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	cmc, _ := cmcproapi.NewCustom("invalid", srv.URL, "v1", &http.Client{}, time.Second)
+	if _, err := cmc.GetCurrencyMapAllActive(); err == nil {
+		t.Error("expected invalid key error")
+	}
+	cmc, _ = cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, "v1",
+		&http.Client{}, 50*time.Millisecond)
+	srv.SetFault("", cmcproapitest.Fault{ErrorCode: cmcproapitest.ErrorCodeMinuteRateLimit, Count: 1})
+	if _, err := cmc.GetCurrencyMapAllActive(); err == nil {
+		t.Error("expected rate limit error")
+	}
+	if _, err := cmc.GetCurrencyMapAllActive(); err != nil {
+		t.Errorf("expected fault to be cleared, got %v", err)
+	}
+	srv.SetLatency(100 * time.Millisecond)
+	if _, err := cmc.GetCurrencyMapAllActive(); err == nil {
+		t.Error("expected timeout error")
+	}
+}
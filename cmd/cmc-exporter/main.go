@@ -0,0 +1,139 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+// Command cmc-exporter serves CoinMarketCap market data and client metrics
+// for Prometheus.
+//
+// Usage:
+//
+//	cmc-exporter --id 1,1027 --convert USD,EUR --listen :9101
+//
+// The API key is read from --key, --key-file or the CMC_PRO_API_KEY environment
+// variable. The key file, e.g. a mounted secret, is read again when it changes.
+// With --sandbox the public sandbox key is used by default.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/exporter"
+	"github.com/nikchis/cmc-proapi/poller"
+)
+
+const envApiKey = "CMC_PRO_API_KEY"
+
+type config struct {
+	listen   string
+	key      string
+	keyFile  string
+	sandbox  bool
+	baseURL  string
+	ids      []int
+	symbols  []string
+	convert  []string
+	interval time.Duration
+	global   bool
+}
+
+func parse(args []string, stderr io.Writer) (cfg config, err error) {
+	fs := flag.NewFlagSet("cmc-exporter", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.listen, "listen", ":9101", "address to serve /metrics on")
+	fs.StringVar(&cfg.key, "key", "", "API key, defaults to $"+envApiKey)
+	fs.StringVar(&cfg.keyFile, "key-file", "", "file containing the API key, re-read on change")
+	fs.BoolVar(&cfg.sandbox, "sandbox", false, "use the sandbox environment and its public key")
+	fs.StringVar(&cfg.baseURL, "base-url", "", "override API base URL")
+	fs.DurationVar(&cfg.interval, "interval", poller.DefaultInterval, "polling interval")
+	fs.BoolVar(&cfg.global, "global", true, "export global market metrics")
+	id := fs.String("id", "1", "comma-separated ids of exported cryptocurrencies")
+	symbol := fs.String("symbol", "", "comma-separated symbols of exported cryptocurrencies")
+	convert := fs.String("convert", "USD", "comma-separated convert symbols")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	for _, v := range strings.Split(*id, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid id %q", v)
+		}
+		cfg.ids = append(cfg.ids, n)
+	}
+	if *symbol != "" {
+		cfg.symbols = strings.Split(*symbol, ",")
+	}
+	cfg.convert = strings.Split(*convert, ",")
+	if cfg.key == "" && cfg.keyFile == "" {
+		cfg.key = os.Getenv(envApiKey)
+	}
+	if cfg.key == "" && cfg.keyFile == "" && !cfg.sandbox {
+		return cfg, errors.New("API key is required, set --key, --key-file or $" + envApiKey)
+	}
+	return
+}
+
+// setup returns the metrics handler and the poller feeding it.
+func setup(cfg config) (http.Handler, *poller.Poller, error) {
+	env := cmcproapi.Production
+	if cfg.sandbox {
+		env = cmcproapi.Sandbox
+	}
+	var key interface{} = cfg.key
+	if cfg.keyFile != "" {
+		key = cmcproapi.NewFileKey(cfg.keyFile)
+	}
+	e := exporter.New()
+	cmc, err := cmcproapi.NewCustom(key, cfg.baseURL, cmcproapi.ApiVersion,
+		&http.Client{Transport: e.Transport(nil)}, cmcproapi.ApiRequestTimeout*time.Second, env)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := poller.New(cmc, poller.Options{
+		Interval: cfg.interval,
+		Convert:  cfg.convert,
+		Global:   cfg.global,
+	})
+	p.Watch(cfg.ids...)
+	p.WatchSymbols(cfg.symbols...)
+	p.OnTick(func(tick poller.Tick) {
+		if tick.Err != nil {
+			log.Println("poll:", tick.Err)
+		}
+	})
+	e.Attach(p)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	return mux, p, nil
+}
+
+func main() {
+	cfg, err := parse(os.Args[1:], os.Stderr)
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(os.Stderr, "cmc-exporter:", err)
+		}
+		os.Exit(2)
+	}
+	handler, p, err := setup(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	p.Start(context.Background())
+	defer p.Stop()
+	log.Println("serving metrics on", cfg.listen)
+	log.Fatal(http.ListenAndServe(cfg.listen, handler))
+}
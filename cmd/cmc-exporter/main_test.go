@@ -0,0 +1,45 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+func TestSetup(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	cfg, err := parse([]string{"--key", cmcproapitest.APIKey, "--base-url", srv.URL,
+		"--id", "1,1027", "--convert", "USD"}, ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, p, err := setup(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticks, _ := p.Subscribe(1)
+	p.Start(context.Background())
+	<-ticks
+	p.Stop()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), `cmc_price{id="1027",symbol="ETH",convert="USD"}`) {
+		t.Errorf("unexpected metrics:\n%s", rec.Body.String())
+	}
+}
+
+func TestParseKeyFile(t *testing.T) {
+	cfg, err := parse([]string{"--key-file", "/run/secrets/cmc"}, ioutil.Discard)
+	if err != nil || cfg.keyFile != "/run/secrets/cmc" {
+		t.Errorf("unexpected config %+v, %v", cfg, err)
+	}
+}
@@ -0,0 +1,281 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+)
+
+func runMap(o *options, fs *flag.FlagSet, args []string, w io.Writer) error {
+	status := fs.String("status", "active", "listing status: active, inactive, untracked")
+	start := fs.Int("start", 1, "first row")
+	limit := fs.Int("limit", 100, "number of rows")
+	symbol := fs.String("symbol", "", "comma-separated symbols")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cmc, err := o.client()
+	if err != nil {
+		return err
+	}
+	query := cmcproapi.CurrencyMapQuery{Start: *start, Limit: *limit, Symbols: splitStrings(*symbol)}
+	for _, s := range splitStrings(*status) {
+		query.ListingStatus = append(query.ListingStatus, cmcproapi.ListingStatus(s))
+	}
+	result, err := cmc.GetCurrencyMapByQuery(context.Background(), query)
+	if err != nil {
+		return err
+	}
+	t := &table{header: []string{"id", "symbol", "name", "slug", "active", "platform"}}
+	for _, c := range result {
+		platform := ""
+		if c.Platform != nil {
+			platform = c.Platform.Symbol
+		}
+		t.add(itoa(c.Id), c.Symbol, c.Name, c.Slug, itoa(c.IsActive), platform)
+	}
+	return write(w, o.output, result, t)
+}
+
+func runInfo(o *options, fs *flag.FlagSet, args []string, w io.Writer) error {
+	id := fs.String("id", "", "comma-separated ids")
+	slug := fs.String("slug", "", "comma-separated slugs")
+	address := fs.String("address", "", "token contract address")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	ids, err := splitInts(*id)
+	if err != nil {
+		return err
+	}
+	cmc, err := o.client()
+	if err != nil {
+		return err
+	}
+	result, err := cmc.GetCurrencyInfoByQuery(context.Background(), cmcproapi.CurrencyInfoQuery{
+		Ids: ids, Slugs: splitStrings(*slug), Symbols: fs.Args(), Address: *address,
+	})
+	if err != nil {
+		return err
+	}
+	list := make([]cmcproapi.CurrencyInfo, 0, len(result))
+	for _, c := range result {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+	t := &table{header: []string{"id", "symbol", "name", "category", "slug", "date_added", "website"}}
+	for _, c := range list {
+		website := ""
+		if c.Urls != nil && len(c.Urls.Website) != 0 {
+			website = c.Urls.Website[0]
+		}
+		t.add(itoa(c.Id), c.Symbol, c.Name, c.Category, c.Slug,
+			timestamp(time.Time(c.DateAdded)), website)
+	}
+	return write(w, o.output, result, t)
+}
+
+func runQuotes(o *options, fs *flag.FlagSet, args []string, w io.Writer) error {
+	id := fs.String("id", "", "comma-separated ids")
+	slug := fs.String("slug", "", "comma-separated slugs")
+	convert := fs.String("convert", "USD", "comma-separated convert symbols or ids")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	ids, err := splitInts(*id)
+	if err != nil {
+		return err
+	}
+	query := cmcproapi.CurrencyQuotesQuery{Ids: ids, Slugs: splitStrings(*slug), Symbols: fs.Args()}
+	converts, err := setConvert(&query.Convert, &query.ConvertIds, *convert)
+	if err != nil {
+		return err
+	}
+	cmc, err := o.client()
+	if err != nil {
+		return err
+	}
+	result, err := cmc.GetCurrencyQuotesLatestByQuery(context.Background(), query)
+	if err != nil {
+		return err
+	}
+	list := make([]cmcproapi.CurrencyListing, 0, len(result))
+	for _, c := range result {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+	t := listingTable(list, converts)
+	return write(w, o.output, result, t)
+}
+
+func runListings(o *options, fs *flag.FlagSet, args []string, w io.Writer) error {
+	start := fs.Int("start", 1, "first row")
+	limit := fs.Int("limit", 100, "number of rows")
+	sortBy := fs.String("sort", "", "sort field, e.g. market_cap, volume_24h, percent_change_24h")
+	convert := fs.String("convert", "USD", "comma-separated convert symbols or ids")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	query := cmcproapi.CurrencyListingsQuery{Start: *start, Limit: *limit, Sort: *sortBy}
+	converts, err := setConvert(&query.Convert, &query.ConvertIds, *convert)
+	if err != nil {
+		return err
+	}
+	cmc, err := o.client()
+	if err != nil {
+		return err
+	}
+	result, err := cmc.GetCurrencyListingsLatestByQuery(context.Background(), query)
+	if err != nil {
+		return err
+	}
+	return write(w, o.output, result, listingTable(result, converts))
+}
+
+func runGlobal(o *options, fs *flag.FlagSet, args []string, w io.Writer) error {
+	convert := fs.String("convert", "USD", "comma-separated convert symbols or ids")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var query cmcproapi.GlobalQuotesQuery
+	converts, err := setConvert(&query.Convert, &query.ConvertIds, *convert)
+	if err != nil {
+		return err
+	}
+	cmc, err := o.client()
+	if err != nil {
+		return err
+	}
+	result, err := cmc.GetGlobalQuotesLatestByQuery(context.Background(), query)
+	if err != nil {
+		return err
+	}
+	t := &table{header: []string{"convert", "total_market_cap", "total_volume_24h",
+		"altcoin_market_cap", "btc_dominance", "eth_dominance", "active_cryptocurrencies"}}
+	for _, key := range converts {
+		var q cmcproapi.GlobalQuote
+		if result.Quote != nil {
+			q = (*result.Quote)[key]
+		}
+		t.add(key, ftoa2(q.TotalMarketCap), ftoa2(q.TotalVolume24h), ftoa2(q.AltcoinMarketCap),
+			ftoa2(result.BtcDominance), ftoa2(result.EthDominance),
+			itoa(result.ActiveCryptocurrencies))
+	}
+	return write(w, o.output, result, t)
+}
+
+func runConvert(o *options, fs *flag.FlagSet, args []string, w io.Writer) error {
+	at := fs.String("time", "", "historical conversion time, RFC 3339 or unix seconds")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 3 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	amount, err := strconv.ParseFloat(fs.Arg(0), 64)
+	if err != nil {
+		return errors.New("invalid amount " + fs.Arg(0))
+	}
+	query := cmcproapi.PriceConversionQuery{Amount: amount}
+	if *at != "" {
+		if query.Time, err = parseTime(*at); err != nil {
+			return err
+		}
+	}
+	if query.Id, err = strconv.Atoi(fs.Arg(1)); err != nil {
+		query.Symbol = strings.ToUpper(fs.Arg(1))
+	}
+	converts, err := setConvert(&query.Convert, &query.ConvertIds, fs.Arg(2))
+	if err != nil {
+		return err
+	}
+	cmc, err := o.client()
+	if err != nil {
+		return err
+	}
+	result, err := cmc.GetPriceConversionByQuery(context.Background(), query)
+	if err != nil {
+		return err
+	}
+	t := &table{header: []string{"symbol", "amount", "convert", "price", "last_updated"}}
+	for _, key := range converts {
+		var q cmcproapi.ConversionQuote
+		if result.Quote != nil {
+			q = (*result.Quote)[key]
+		}
+		t.add(result.Symbol, ftoa(result.Amount), key, ftoa(q.Price),
+			timestamp(time.Time(q.LastUpdated)))
+	}
+	return write(w, o.output, result, t)
+}
+
+func runKey(o *options, fs *flag.FlagSet, args []string, w io.Writer) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cmc, err := o.client()
+	if err != nil {
+		return err
+	}
+	result, err := cmc.GetKeyInfo()
+	if err != nil {
+		return err
+	}
+	u := result.Usage
+	t := &table{header: []string{"period", "limit", "used", "left", "reset"}}
+	t.add("minute", itoa(result.Plan.RateLimitMinute), itoa(u.CurrentMinute.RequestsMade),
+		itoa(u.CurrentMinute.RequestsLeft), "")
+	t.add("day", itoa(result.Plan.CreditLimitDaily), itoa(u.CurrentDay.CreditsUsed),
+		itoa(u.CurrentDay.CreditsLeft), result.Plan.CreditLimitDailyReset)
+	t.add("month", itoa(result.Plan.CreditLimitMonthly), itoa(u.CurrentMonth.CreditsUsed),
+		itoa(u.CurrentMonth.CreditsLeft), result.Plan.CreditLimitMonthlyReset)
+	return write(w, o.output, result, t)
+}
+
+// setConvert fills either convert symbols or ids from comma-separated list
+// and returns the keys of the quote map in the requested order.
+func setConvert(symbols *[]string, ids *[]int, list string) (keys []string, err error) {
+	for _, v := range splitStrings(list) {
+		v = strings.ToUpper(strings.TrimSpace(v))
+		if id, err := strconv.Atoi(v); err == nil {
+			*ids = append(*ids, id)
+		} else {
+			*symbols = append(*symbols, v)
+		}
+		keys = append(keys, v)
+	}
+	if len(*symbols) != 0 && len(*ids) != 0 {
+		return nil, errors.New("convert must be either symbols or ids")
+	}
+	return
+}
+
+// listingTable returns one row per cryptocurrency and convert option.
+func listingTable(list []cmcproapi.CurrencyListing, converts []string) *table {
+	t := &table{header: []string{"rank", "id", "symbol", "name", "convert", "price",
+		"market_cap", "volume_24h", "change_1h", "change_24h", "change_7d"}}
+	for _, c := range list {
+		for _, key := range converts {
+			var q cmcproapi.CurrencyQuote
+			if c.Quote != nil {
+				q = (*c.Quote)[key]
+			}
+			t.add(itoa(c.CmcRank), itoa(c.Id), c.Symbol, c.Name, key, ftoa(q.Price),
+				ftoa2(q.MarketCap), ftoa2(q.Volume24h), ftoa2(q.PercentChange1h),
+				ftoa2(q.PercentChange24h), ftoa2(q.PercentChange7d))
+		}
+	}
+	return t
+}
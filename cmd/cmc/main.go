@@ -0,0 +1,135 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+// Command cmc queries the CoinMarketCap API from the command line.
+//
+// Usage:
+//
+//	cmc [flags] <command> [flags] [arguments]
+//
+// Commands:
+//
+//	map                          list cryptocurrencies and their ids
+//	info SYMBOL...               show static metadata
+//	quotes --id 1,1027           show latest quotes
+//	listings --limit 100         show latest listings ranked by market cap
+//	global                       show global market metrics
+//	convert AMOUNT FROM TO[,TO]  convert an amount between currencies
+//	key                          show API key plan and usage
+//
+// The API key is read from --key or the CMC_PRO_API_KEY environment variable,
+// with --sandbox the public sandbox key is used by default.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+)
+
+const envApiKey = "CMC_PRO_API_KEY"
+
+// options are flags shared by all commands.
+type options struct {
+	key     string
+	sandbox bool
+	baseURL string
+	output  string
+	timeout time.Duration
+}
+
+// register adds shared flags to fs, so they are accepted both before and after the command.
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.key, "key", o.key, "API key, defaults to $"+envApiKey)
+	fs.BoolVar(&o.sandbox, "sandbox", o.sandbox, "use the sandbox environment and its public key")
+	fs.StringVar(&o.baseURL, "base-url", o.baseURL, "override API base URL")
+	fs.StringVar(&o.output, "output", o.output, "output format: table, json or csv")
+	fs.DurationVar(&o.timeout, "timeout", o.timeout, "request timeout")
+}
+
+// client returns Client configured by options.
+func (o *options) client() (*cmcproapi.Client, error) {
+	env := cmcproapi.Production
+	if o.sandbox {
+		env = cmcproapi.Sandbox
+	}
+	key := o.key
+	if key == "" {
+		key = os.Getenv(envApiKey)
+	}
+	if key == "" && !o.sandbox {
+		return nil, errors.New("API key is required, set --key or $" + envApiKey)
+	}
+	return cmcproapi.NewCustom(key, o.baseURL, cmcproapi.ApiVersion, &http.Client{}, o.timeout, env)
+}
+
+type command struct {
+	usage string
+	run   func(o *options, fs *flag.FlagSet, args []string, w io.Writer) error
+}
+
+var commands = map[string]command{
+	"map":      {"map [--status active] [--start 1] [--limit 100] [--symbol BTC,ETH]", runMap},
+	"info":     {"info [--id 1,1027 | --slug bitcoin | SYMBOL...]", runInfo},
+	"quotes":   {"quotes [--id 1,1027 | --slug bitcoin | SYMBOL...] [--convert USD]", runQuotes},
+	"listings": {"listings [--start 1] [--limit 100] [--convert USD]", runListings},
+	"global":   {"global [--convert USD]", runGlobal},
+	"convert":  {"convert [--time 2019-01-01T00:00:00Z] AMOUNT FROM TO[,TO]", runConvert},
+	"key":      {"key", runKey},
+}
+
+var commandOrder = []string{"map", "info", "quotes", "listings", "global", "convert", "key"}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: cmc [--key KEY] [--sandbox] [--base-url URL] [--output table|json|csv] <command>")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, name := range commandOrder {
+		fmt.Fprintln(w, "  cmc", commands[name].usage)
+	}
+}
+
+// run executes the command line args writing results to stdout.
+func run(args []string, stdout, stderr io.Writer) error {
+	o := &options{output: "table", timeout: cmcproapi.ApiRequestTimeout * time.Second}
+	global := flag.NewFlagSet("cmc", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() { usage(stderr) }
+	o.register(global)
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	if global.NArg() == 0 {
+		usage(stderr)
+		return flag.ErrHelp
+	}
+	name := global.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		usage(stderr)
+		return fmt.Errorf("unknown command %q", name)
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: cmc", cmd.usage)
+		fs.PrintDefaults()
+	}
+	o.register(fs)
+	return cmd.run(o, fs, global.Args()[1:], stdout)
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(os.Stderr, "cmc:", err)
+		}
+		os.Exit(2)
+	}
+}
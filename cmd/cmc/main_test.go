@@ -0,0 +1,84 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+func TestRun(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"map", "--limit", "2"}, "Ethereum"},
+		{[]string{"info", "BTC"}, "bitcoin"},
+		{[]string{"--output", "csv", "quotes", "--id", "1,1027", "--convert", "USD,EUR"}, "1027,ETH,Ethereum,EUR"},
+		{[]string{"listings", "--limit", "3", "--output", "json"}, `"symbol": "XRP"`},
+		{[]string{"global"}, "USD"},
+		{[]string{"convert", "2.5", "BTC", "EUR"}, "EUR"},
+		{[]string{"convert", "--time", "1514764800", "1", "ETH", "USD"}, "2018-01-01T00:00:00Z"},
+		{[]string{"key"}, "month"},
+	} {
+		var out bytes.Buffer
+		args := append([]string{"--key", cmcproapitest.APIKey, "--base-url", srv.URL}, tc.args...)
+		if err := run(args, &out, ioutil.Discard); err != nil {
+			t.Errorf("%v: %v", tc.args, err)
+			continue
+		}
+		if !strings.Contains(out.String(), tc.want) {
+			t.Errorf("%v: expected %q in output:\n%s", tc.args, tc.want, out.String())
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	if err := run([]string{"unknown"}, ioutil.Discard, ioutil.Discard); err == nil {
+		t.Error("expected unknown command error")
+	}
+	if err := run([]string{"--key", "k", "convert", "x", "BTC", "USD"},
+		ioutil.Discard, ioutil.Discard); err == nil {
+		t.Error("expected invalid amount error")
+	}
+}
+
+func TestRunJSONTimes(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	stamp := regexp.MustCompile(`"(?:last_updated|date_added)": "(\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ)"`)
+	for _, args := range [][]string{
+		{"global"},
+		{"quotes", "--id", "1"},
+		{"info", "BTC"},
+	} {
+		var out bytes.Buffer
+		args = append([]string{"--key", cmcproapitest.APIKey, "--base-url", srv.URL,
+			"--output", "json"}, args...)
+		if err := run(args, &out, ioutil.Discard); err != nil {
+			t.Errorf("%v: %v", args, err)
+			continue
+		}
+		if strings.Contains(out.String(), "{}") {
+			t.Errorf("%v: empty object in output:\n%s", args, out.String())
+		}
+		m := stamp.FindStringSubmatch(out.String())
+		if m == nil {
+			t.Errorf("%v: no timestamp in output:\n%s", args, out.String())
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, m[1]); err != nil {
+			t.Errorf("%v: %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// flight is an upstream request shared by concurrent identical calls.
+type flight struct {
+	done     chan struct{}
+	cancel   context.CancelFunc
+	waiters  int
+	response Response
+	err      error
+}
+
+// fetch acts identically to roundTrip, except that concurrent calls with the same
+// version, endpoint and query share a single upstream request.
+//
+// The shared request keeps the values of the context of the caller which
+// started it, e.g. for hooks, and is canceled when the last of its callers
+// gives up, so one caller giving up does not fail the others. Only the caller
+// which started the request gets its CreditCount, the others receive zero as
+// no extra credits were spent.
+func (c *Client) fetch(
+	ctx context.Context, version, endpoint string, query url.Values) (Response, error) {
+	key := version + "/" + endpoint + "?" + query.Encode()
+	c.mu.Lock()
+	f, shared := c.flights[key]
+	if !shared {
+		if c.flights == nil {
+			c.flights = make(map[string]*flight)
+		}
+		fctx, cancel := context.WithCancel(detached{ctx})
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		c.flights[key] = f
+		go func() {
+			f.response, f.err = c.roundTrip(fctx, version, endpoint, query)
+			c.mu.Lock()
+			if c.flights[key] == f {
+				delete(c.flights, key)
+			}
+			c.mu.Unlock()
+			cancel()
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	c.mu.Unlock()
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		c.leave(key, f)
+		return Response{}, ctx.Err()
+	}
+	response := f.response
+	if shared {
+		response.Status.CreditCount = 0
+	}
+	return response, f.err
+}
+
+// leave removes a caller which gave up from the flight and cancels the
+// request when no caller is left. Later calls start a new request.
+func (c *Client) leave(key string, f *flight) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f.waiters--; f.waiters == 0 {
+		if c.flights[key] == f {
+			delete(c.flights, key)
+		}
+		f.cancel()
+	}
+}
+
+// detached keeps values of the context but not its cancellation, so a request
+// shared by several callers survives the one which started it giving up.
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detached) Done() <-chan struct{} { return nil }
+
+func (detached) Err() error { return nil }
@@ -0,0 +1,97 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchCoalescing(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, `{"data":{"total_cryptocurrencies":1},"status":{"error_code":0,"credit_count":1}}`)
+	}))
+	defer srv.Close()
+	cmc, _ := NewCustom(TestApiKey, srv.URL, ApiVersion, &http.Client{}, time.Second)
+	var wg sync.WaitGroup
+	var credits int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var status ResponseStatus
+			q := GlobalQuotesQuery{Convert: []string{"USD"}}
+			v, _ := q.Values()
+			if _, err := cmc.handleRequest(ltUriGlobalQuotesLatest, &v, &status); err != nil {
+				t.Error(err)
+			}
+			atomic.AddInt32(&credits, int32(status.CreditCount))
+		}()
+	}
+	wg.Wait()
+	if calls != 1 || credits != 1 {
+		t.Errorf("expected one upstream call and credit, got %d calls and %d credits",
+			calls, credits)
+	}
+}
+
+func TestFetchCancel(t *testing.T) {
+	canceled := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			canceled <- struct{}{}
+		case <-release:
+			fmt.Fprint(w, `{"data":{},"status":{"error_code":0,"credit_count":1}}`)
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+	cmc, _ := NewCustom(TestApiKey, srv.URL, ApiVersion, &http.Client{}, time.Minute)
+
+	// the request of a single caller is canceled along with its context
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := cmc.fetch(ctx, ApiVersion, ltUriKeyInfo, nil); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("upstream request not canceled")
+	}
+
+	// a shared request survives all but the last caller giving up
+	first, cancelFirst := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := cmc.fetch(first, ApiVersion, ltUriKeyInfo, nil)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	go func() {
+		_, err := cmc.fetch(context.Background(), ApiVersion, ltUriKeyInfo, nil)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancelFirst()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected canceled first caller, got %v", err)
+	}
+	release <- struct{}{}
+	if err := <-done; err != nil {
+		t.Errorf("shared request failed: %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Converter converts amounts between currencies locally using rates of
+// listings or quotes snapshots, e.g. of GetCurrencyListingsLatestAll fetched
+// in BTC and USD. Conversions without a path of known rates fall back to
+// tools/price-conversion and the fetched rate is kept for later use.
+//
+//	cv := cmcproapi.NewConverter(cmc)
+//	cv.AddListings(listings...)
+//	res, err := cv.Convert(ctx, 2.5, "ETH", "XRP")
+//	fmt.Println(res.Result, res.Path, res.Age())
+type Converter struct {
+	// MaxAge excludes rates updated longer ago, no rate is excluded if zero.
+	MaxAge time.Duration
+
+	client *Client
+
+	mu     sync.RWMutex
+	rates  map[string]map[string]rate
+	owners map[string]*symbolOwner
+}
+
+// symbolOwner is the listing whose quotes provide the rates of a symbol, and
+// the currencies it has rates in.
+type symbolOwner struct {
+	id       int
+	rank     int
+	converts map[string]bool
+}
+
+// rate is an edge of the conversion graph.
+type rate struct {
+	value   float64
+	updated time.Time
+}
+
+// Conversion is the result of Converter.Convert.
+//
+// Path lists currencies from the source to the target. Updated is the time of
+// the oldest rate used. Remote is set if the rate was fetched from the API.
+type Conversion struct {
+	Amount  float64
+	From    string
+	To      string
+	Result  float64
+	Rate    float64
+	Path    []string
+	Updated time.Time
+	Remote  bool
+}
+
+// Age returns how long ago the oldest rate used was updated.
+func (c Conversion) Age() time.Duration {
+	return time.Since(c.Updated)
+}
+
+// NewConverter returns an empty Converter, c is used for fallback requests
+// and may be nil to convert strictly offline.
+func NewConverter(c *Client) *Converter {
+	return &Converter{client: c, rates: map[string]map[string]rate{},
+		owners: map[string]*symbolOwner{}}
+}
+
+// AddRate sets the rate of one unit of from in to, the reverse rate is
+// derived from it.
+func (cv *Converter) AddRate(from, to string, value float64, updated time.Time) {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	cv.addRate(strings.ToUpper(from), strings.ToUpper(to), value, updated)
+}
+
+// addRate sets the rate and its reverse, the caller holds the lock.
+func (cv *Converter) addRate(from, to string, value float64, updated time.Time) {
+	if value <= 0 {
+		return
+	}
+	cv.set(from, to, rate{value: value, updated: updated})
+	cv.set(to, from, rate{value: 1 / value, updated: updated})
+}
+
+// set stores the edge unless a more recent one is known.
+func (cv *Converter) set(from, to string, r rate) {
+	edges, ok := cv.rates[from]
+	if !ok {
+		edges = map[string]rate{}
+		cv.rates[from] = edges
+	}
+	if old, ok := edges[to]; ok && old.updated.After(r.updated) {
+		return
+	}
+	edges[to] = r
+}
+
+// AddListings adds rates of all quotes of the listings. Symbols are not
+// unique, so of listings sharing a symbol only the best ranked by cmc_rank
+// provides its rates, the others are skipped.
+func (cv *Converter) AddListings(listings ...CurrencyListing) {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	for _, l := range listings {
+		if l.Quote == nil {
+			continue
+		}
+		owner := cv.owner(l)
+		if owner == nil {
+			continue
+		}
+		symbol := strings.ToUpper(l.Symbol)
+		for convert, q := range *l.Quote {
+			updated := time.Time(q.LastUpdated)
+			if updated.IsZero() {
+				updated = time.Time(l.LastUpdated)
+			}
+			convert = strings.ToUpper(convert)
+			owner.converts[convert] = true
+			cv.addRate(symbol, convert, q.Price, updated)
+		}
+	}
+}
+
+// owner returns the owner of the symbol of the listing, nil if another
+// listing with the symbol is ranked better. Rates of a replaced owner are
+// dropped.
+func (cv *Converter) owner(l CurrencyListing) *symbolOwner {
+	symbol := strings.ToUpper(l.Symbol)
+	owner, ok := cv.owners[symbol]
+	if ok && owner.id != l.Id {
+		if !rankedBefore(l.CmcRank, l.Id, owner.rank, owner.id) {
+			return nil
+		}
+		for convert := range owner.converts {
+			delete(cv.rates[symbol], convert)
+			delete(cv.rates[convert], symbol)
+		}
+		ok = false
+	}
+	if !ok {
+		owner = &symbolOwner{id: l.Id, converts: map[string]bool{}}
+		cv.owners[symbol] = owner
+	}
+	owner.rank = l.CmcRank
+	return owner
+}
+
+// rankedBefore reports whether a cryptocurrency of rank a and id aId ranks
+// before one of rank b and id bId, with unranked ones last as in Resolver.
+func rankedBefore(a, aId, b, bId int) bool {
+	if a != b {
+		return b == 0 || (a != 0 && a < b)
+	}
+	return aId < bId
+}
+
+// Convert converts amount of from into to. Currencies are given by symbol.
+func (cv *Converter) Convert(ctx context.Context, amount float64, from, to string) (res Conversion, err error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	res = Conversion{Amount: amount, From: from, To: to}
+	if from == to {
+		res.Result, res.Rate, res.Path, res.Updated = amount, 1, []string{from}, time.Now()
+		return
+	}
+	cv.mu.RLock()
+	path, ok := cv.path(from, to)
+	if ok {
+		res.Rate, res.Path = 1, path
+		for i := 1; i < len(path); i++ {
+			r := cv.rates[path[i-1]][path[i]]
+			res.Rate *= r.value
+			if i == 1 || r.updated.Before(res.Updated) {
+				res.Updated = r.updated
+			}
+		}
+	}
+	cv.mu.RUnlock()
+	if !ok {
+		if cv.client == nil {
+			return res, errors.New(ltMsgConvertNoRate + ": " + from + " to " + to)
+		}
+		var r rate
+		if r, err = cv.fetch(ctx, from, to); err != nil {
+			return
+		}
+		cv.AddRate(from, to, r.value, r.updated)
+		res.Rate, res.Path, res.Updated, res.Remote = r.value, []string{from, to}, r.updated, true
+	}
+	res.Result = amount * res.Rate
+	return
+}
+
+// path returns the shortest path of usable rates from one currency to another.
+func (cv *Converter) path(from, to string) ([]string, bool) {
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) != 0 {
+		node := queue[0]
+		queue = queue[1:]
+		next := make([]string, 0, len(cv.rates[node]))
+		for k, r := range cv.rates[node] {
+			if cv.MaxAge > 0 && time.Since(r.updated) > cv.MaxAge {
+				continue
+			}
+			next = append(next, k)
+		}
+		sort.Strings(next)
+		for _, k := range next {
+			if _, seen := prev[k]; seen {
+				continue
+			}
+			prev[k] = node
+			if k == to {
+				path := []string{to}
+				for n := node; n != ""; n = prev[n] {
+					path = append([]string{n}, path...)
+				}
+				return path, true
+			}
+			queue = append(queue, k)
+		}
+	}
+	return nil, false
+}
+
+// fetch requests the rate from tools/price-conversion.
+func (cv *Converter) fetch(ctx context.Context, from, to string) (r rate, err error) {
+	var result PriceConversion
+	var q url.Values
+	query := PriceConversionQuery{Amount: 1, Symbol: from, Convert: []string{to}}
+	if q, err = query.Values(); err != nil {
+		return
+	}
+	if err = cv.client.decodeRequest(&result, ctx, ltUriToolsPriceConversion, &q); err != nil {
+		return
+	}
+	if result.Quote != nil {
+		if quote, ok := (*result.Quote)[to]; ok && quote.Price > 0 {
+			return rate{value: quote.Price, updated: time.Time(quote.LastUpdated)}, nil
+		}
+	}
+	return r, errors.New(ltMsgConvertNoRate + ": " + from + " to " + to)
+}
@@ -0,0 +1,75 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestConverter(t *testing.T) {
+	cmc, _ := NewTest()
+	listings, err := cmc.GetCurrencyListingsLatestAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cv := NewConverter(cmc)
+	cv.AddListings(listings...)
+	ctx := context.Background()
+	prices := map[string]float64{}
+	for _, l := range listings {
+		prices[l.Symbol] = (*l.Quote)["USD"].Price
+	}
+
+	res, err := cv.Convert(ctx, 2, "eth", "XRP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 2 * prices["ETH"] / prices["XRP"]; math.Abs(res.Result-want) > 1e-6*want {
+		t.Errorf("Convert(ETH, XRP) = %v, want %v", res.Result, want)
+	}
+	if len(res.Path) != 3 || res.Path[1] != "BTC" || res.Remote || res.Updated.IsZero() {
+		t.Errorf("unexpected conversion: %+v", res)
+	}
+	if res, _ = cv.Convert(ctx, 1000, "USD", "BTC"); math.Abs(res.Result-1000/prices["BTC"]) > 1e-9 {
+		t.Errorf("Convert(USD, BTC) = %v", res.Result)
+	}
+
+	calls := testServer.Calls(EndpointToolsPriceConversion)
+	if res, err = cv.Convert(ctx, 1, "ETH", "EUR"); err != nil || !res.Remote || res.Result == 0 {
+		t.Fatalf("fallback Convert(ETH, EUR) = %+v, %v", res, err)
+	}
+	if res, err = cv.Convert(ctx, 1, "EUR", "XRP"); err != nil || res.Remote {
+		t.Errorf("Convert(EUR, XRP) = %+v, %v", res, err)
+	}
+	if n := testServer.Calls(EndpointToolsPriceConversion) - calls; n != 1 {
+		t.Errorf("fallback requested %d times", n)
+	}
+
+	offline := NewConverter(nil)
+	offline.AddListings(listings...)
+	offline.MaxAge = time.Hour
+	if _, err = offline.Convert(ctx, 1, "ETH", "USD"); err == nil {
+		t.Error("stale rates were used")
+	}
+}
+
+func TestConverterSharedSymbol(t *testing.T) {
+	now := time.Now()
+	listing := func(id, rank int, price float64, updated time.Time) CurrencyListing {
+		return CurrencyListing{Id: id, Symbol: "BTC", CmcRank: rank,
+			Quote: &CurrencyQuoteMap{"USD": {Price: price, LastUpdated: jsonTime(updated)}}}
+	}
+	btc, clone := listing(1, 1, 100, now.Add(-time.Minute)), listing(3602, 900, 0.5, now)
+	for _, order := range [][]CurrencyListing{{btc, clone}, {clone, btc}} {
+		cv := NewConverter(nil)
+		cv.AddListings(order...)
+		if res, err := cv.Convert(context.Background(), 1, "BTC", "USD"); err != nil || res.Result != 100 {
+			t.Errorf("Convert(BTC, USD) = %+v, %v", res, err)
+		}
+	}
+}
@@ -5,6 +5,7 @@
 package cmcproapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 )
@@ -22,10 +23,14 @@ type CurrencyMap struct {
 	Name                string            `json:"name"`
 	Symbol              string            `json:"symbol"`
 	Slug                string            `json:"slug"`
+	Rank                int               `json:"rank,omitempty"`
 	IsActive            int               `json:"is_active"`
 	FirstHistoricalData jsonTime          `json:"first_historical_data"`
 	LastHistoricalData  jsonTime          `json:"last_historical_data"`
 	Platform            *CurrencyPlatform `json:"platform"`
+
+	// set on results of the sandbox environment
+	Sandbox bool `json:"sandbox,omitempty"`
 }
 
 type CurrencyPlatform struct {
@@ -63,6 +68,30 @@ type CurrencyInfo struct {
 	Tags        []string          `json:"tags"`
 	Platform    *CurrencyPlatform `json:"platform"`
 	Urls        *CurrencyUrls     `json:"urls"`
+
+	ContractAddress []CurrencyContract `json:"contract_address,omitempty"`
+
+	// set on results of the sandbox environment
+	Sandbox bool `json:"sandbox,omitempty"`
+}
+
+// CurrencyContract is a token contract of a cryptocurrency on one of the chains
+// it is issued on.
+type CurrencyContract struct {
+	ContractAddress string                   `json:"contract_address"`
+	Platform        CurrencyContractPlatform `json:"platform"`
+}
+
+type CurrencyContractPlatform struct {
+	Name string               `json:"name"`
+	Coin CurrencyContractCoin `json:"coin"`
+}
+
+type CurrencyContractCoin struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+	Slug   string `json:"slug"`
 }
 
 type CurrencyListing struct {
@@ -81,8 +110,18 @@ type CurrencyListing struct {
 	Tags                   []string          `json:"tags"`
 	Platform               *CurrencyPlatform `json:"platform"`
 	Quote                  *CurrencyQuoteMap `json:"quote"`
+
+	// exact values decoded in NumberExact mode
+	CirculatingSupplyExact Decimal `json:"circulating_supply_exact,omitempty"`
+	TotalSupplyExact       Decimal `json:"total_supply_exact,omitempty"`
+	MaxSupplyExact         Decimal `json:"max_supply_exact,omitempty"`
+
+	// set on results of the sandbox environment
+	Sandbox bool `json:"sandbox,omitempty"`
 }
 
+type CurrencyListingMap map[string]CurrencyListing
+
 type CurrencyQuoteMap map[string]CurrencyQuote
 
 type CurrencyQuote struct {
@@ -98,6 +137,14 @@ type CurrencyQuote struct {
 	PercentChange24h  float64  `json:"percent_change_24h"`
 	PercentChange7d   float64  `json:"percent_change_7d"`
 	LastUpdated       jsonTime `json:"last_updated"`
+
+	// exact values decoded in NumberExact mode
+	PriceExact     Decimal `json:"price_exact,omitempty"`
+	Volume24hExact Decimal `json:"volume_24h_exact,omitempty"`
+	MarketCapExact Decimal `json:"market_cap_exact,omitempty"`
+
+	// set on results of the sandbox environment
+	Sandbox bool `json:"sandbox,omitempty"`
 }
 
 // GetCurrencyMap returns a mapping of cryptocurrencies to unique CoinMarketCap ids.
@@ -118,7 +165,7 @@ func (c *Client) GetCurrencyMap(
 	if raw, err = c.handleRequest(ltUriCurrencyMap, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
 	return
 }
 
@@ -132,7 +179,24 @@ func (c *Client) GetCurrencyMapAllActive() (result []CurrencyMap, err error) {
 	if raw, err = c.handleRequest(ltUriCurrencyMap, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
+	return
+}
+
+// GetCurrencyMapByQuery acts identically to GetCurrencyMap, except that it
+// uses typed query which is validated before the request is sent, and ctx which
+// cancels the request.
+func (c *Client) GetCurrencyMapByQuery(
+	ctx context.Context, query CurrencyMapQuery) (result []CurrencyMap, err error) {
+	var raw json.RawMessage
+	var q url.Values
+	if q, err = query.Values(); err != nil {
+		return
+	}
+	if raw, err = c.handleRequest(ltUriCurrencyMap, &q, ctx); err != nil {
+		return
+	}
+	err = c.unmarshal(raw, &result)
 	return
 }
 
@@ -147,7 +211,7 @@ func (c *Client) GetCurrencyInfoById(id string) (result CurrencyInfoMap, err err
 	if raw, err = c.handleRequest(ltUriCurrencyInfo, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
 	return
 }
 
@@ -160,7 +224,37 @@ func (c *Client) GetCurrencyInfoBySymbol(symbol string) (result CurrencyInfoMap,
 	if raw, err = c.handleRequest(ltUriCurrencyInfo, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
+	return
+}
+
+// GetCurrencyInfoByAddress acts identically to GetCurrencyInfoById, except that it
+// uses token contract address instead of id as query parameter.
+func (c *Client) GetCurrencyInfoByAddress(address string) (result CurrencyInfoMap, err error) {
+	var raw json.RawMessage
+	q := url.Values{}
+	q.Set("address", address)
+	if raw, err = c.handleRequest(ltUriCurrencyInfo, &q); err != nil {
+		return
+	}
+	err = c.unmarshal(raw, &result)
+	return
+}
+
+// GetCurrencyInfoByQuery acts identically to GetCurrencyInfoById, except that it
+// uses typed query which is validated before the request is sent, and ctx which
+// cancels the request.
+func (c *Client) GetCurrencyInfoByQuery(
+	ctx context.Context, query CurrencyInfoQuery) (result CurrencyInfoMap, err error) {
+	var raw json.RawMessage
+	var q url.Values
+	if q, err = query.Values(); err != nil {
+		return
+	}
+	if raw, err = c.handleRequest(ltUriCurrencyInfo, &q, ctx); err != nil {
+		return
+	}
+	err = c.unmarshal(raw, &result)
 	return
 }
 
@@ -177,7 +271,7 @@ func (c *Client) GetCurrencyListingsLatestById(
 	if raw, err = c.handleRequest(ltUriCurrencyListingsLatest, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
 	return
 }
 
@@ -193,7 +287,7 @@ func (c *Client) GetCurrencyListingsLatestBySymbol(
 	if raw, err = c.handleRequest(ltUriCurrencyListingsLatest, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
 	return
 }
 
@@ -208,7 +302,24 @@ func (c *Client) GetCurrencyListingsLatestAll() (result []CurrencyListing, err e
 	if raw, err = c.handleRequest(ltUriCurrencyListingsLatest, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
+	return
+}
+
+// GetCurrencyListingsLatestByQuery acts identically to GetCurrencyListingsLatestById, except
+// that it uses typed query which is validated before the request is sent, and
+// ctx which cancels the request.
+func (c *Client) GetCurrencyListingsLatestByQuery(
+	ctx context.Context, query CurrencyListingsQuery) (result []CurrencyListing, err error) {
+	var raw json.RawMessage
+	var q url.Values
+	if q, err = query.Values(); err != nil {
+		return
+	}
+	if raw, err = c.handleRequest(ltUriCurrencyListingsLatest, &q, ctx); err != nil {
+		return
+	}
+	err = c.unmarshal(raw, &result)
 	return
 }
 
@@ -222,7 +333,7 @@ func (c *Client) GetCurrencyQuotesLatestById(
 	if raw, err = c.handleRequest(ltUriCurrencyQuotesLatest, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
 	return
 }
 
@@ -237,6 +348,26 @@ func (c *Client) GetCurrencyQuotesLatestBySymbol(
 	if raw, err = c.handleRequest(ltUriCurrencyQuotesLatest, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
+	return
+}
+
+// GetCurrencyQuotesLatestByQuery acts identically to GetCurrencyQuotesLatestById, except
+// that it uses typed query which is validated before the request is sent, and
+// ctx which cancels the request.
+//
+// The result is keyed by id, symbol or slug depending on the query and holds
+// full market data for each cryptocurrency including its quote map.
+func (c *Client) GetCurrencyQuotesLatestByQuery(
+	ctx context.Context, query CurrencyQuotesQuery) (result CurrencyListingMap, err error) {
+	var raw json.RawMessage
+	var q url.Values
+	if q, err = query.Values(); err != nil {
+		return
+	}
+	if raw, err = c.handleRequest(ltUriCurrencyQuotesLatest, &q, ctx); err != nil {
+		return
+	}
+	err = c.unmarshal(raw, &result)
 	return
 }
@@ -0,0 +1,136 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NumberMode selects how numbers of responses are decoded, pass it to NewCustom.
+//
+// With NumberExact the exact decimal text of prices, amounts and supplies is
+// kept in Decimal fields next to their float64 counterparts, e.g. PriceExact
+// next to Price, for code that must not lose precision on micro-cap prices or
+// large supplies. It costs a second decoding pass of every response. Decimal
+// fields are marshalled with the suffix _exact, e.g. as price_exact, so exact
+// values survive JSON output and storage.
+type NumberMode int
+
+const (
+	NumberFloat NumberMode = iota
+	NumberExact
+)
+
+// Decimal is a number exactly as sent by the API, empty if not decoded.
+type Decimal string
+
+// String returns the decimal text.
+func (d Decimal) String() string {
+	return string(d)
+}
+
+// Float64 returns the nearest float64 value.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(string(d), 64)
+	return f
+}
+
+// Rat returns the exact value, ok is false if d is empty or malformed.
+func (d Decimal) Rat() (r *big.Rat, ok bool) {
+	return new(big.Rat).SetString(string(d))
+}
+
+var decimalType = reflect.TypeOf(Decimal(""))
+
+// unmarshal decodes data into result, filling Decimal fields in NumberExact mode
+// and marking results of the sandbox.
+func (c *Client) unmarshal(data []byte, result interface{}) error {
+	if err := json.Unmarshal(data, result); err != nil {
+		return err
+	}
+	if c.Sandbox() {
+		markSandbox(reflect.ValueOf(result))
+	}
+	if c.numbers != NumberExact {
+		return nil
+	}
+	var tree interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&tree); err != nil {
+		return err
+	}
+	fillExact(reflect.ValueOf(result), tree)
+	return nil
+}
+
+// fillExact walks v along with its generic JSON tree and sets each Decimal
+// field named after a float64 field with the suffix Exact.
+func fillExact(v reflect.Value, tree interface{}) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			fillExact(v.Elem(), tree)
+		}
+	case reflect.Struct:
+		obj, ok := tree.(map[string]interface{})
+		if !ok {
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if f.PkgPath != "" || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			value, ok := obj[name]
+			if !ok {
+				continue
+			}
+			if f.Type.Kind() != reflect.Float64 {
+				fillExact(v.Field(i), value)
+				continue
+			}
+			n, ok := value.(json.Number)
+			exact := v.FieldByName(f.Name + "Exact")
+			if ok && exact.IsValid() && exact.Type() == decimalType && exact.CanSet() {
+				exact.SetString(string(n))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		list, ok := tree.([]interface{})
+		if !ok {
+			return
+		}
+		for i := 0; i < v.Len() && i < len(list); i++ {
+			fillExact(v.Index(i), list[i])
+		}
+	case reflect.Map:
+		obj, ok := tree.(map[string]interface{})
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			value, ok := obj[key.String()]
+			if !ok {
+				continue
+			}
+			// map elements are not addressable, update a copy
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			fillExact(elem, value)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNumberExact(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"9999":{"id":9999,"symbol":"MICRO",
+			"circulating_supply":123456789012345678901234,"max_supply":null,
+			"quote":{"USD":{"price":0.000000000001234567890123456789,"market_cap":152415.787}}}},
+			"status":{"error_code":0}}`)
+	}))
+	defer srv.Close()
+
+	cmc, _ := NewCustom(TestApiKey, srv.URL, ApiVersion, &http.Client{}, time.Second)
+	result, err := cmc.GetCurrencyQuotesLatestByQuery(context.Background(),
+		CurrencyQuotesQuery{Ids: []int{9999}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := (*result["9999"].Quote)["USD"]; q.PriceExact != "" || q.Price == 0 {
+		t.Errorf("float mode decoded %+v", q)
+	}
+
+	cmc, _ = NewCustom(TestApiKey, srv.URL, ApiVersion, &http.Client{}, time.Second, NumberExact)
+	result, err = cmc.GetCurrencyQuotesLatestByQuery(context.Background(),
+		CurrencyQuotesQuery{Ids: []int{9999}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := result["9999"]
+	q := (*l.Quote)["USD"]
+	if q.PriceExact != "0.000000000001234567890123456789" || q.MarketCapExact != "152415.787" {
+		t.Errorf("unexpected quote: %+v", q)
+	}
+	if l.CirculatingSupplyExact != "123456789012345678901234" || l.MaxSupplyExact != "" {
+		t.Errorf("unexpected supplies: %q, %q", l.CirculatingSupplyExact, l.MaxSupplyExact)
+	}
+	supply, ok := l.CirculatingSupplyExact.Rat()
+	want, _ := new(big.Rat).SetString("123456789012345678901234")
+	if !ok || supply.Cmp(want) != 0 {
+		t.Errorf("Rat() = %v, %v", supply, ok)
+	}
+	if q.PriceExact.Float64() != q.Price {
+		t.Errorf("Float64() = %v, want %v", q.PriceExact.Float64(), q.Price)
+	}
+
+	data, _ := json.Marshal(l)
+	var decoded CurrencyListing
+	if err = json.Unmarshal(data, &decoded); err != nil ||
+		(*decoded.Quote)["USD"].PriceExact != q.PriceExact ||
+		decoded.CirculatingSupplyExact != l.CirculatingSupplyExact {
+		t.Errorf("exact values lost in JSON %s", data)
+	}
+}
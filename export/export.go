@@ -0,0 +1,206 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+// Package export flattens API results into tables and writes them as CSV or NDJSON.
+//
+// Nested objects become prefixed columns, e.g. platform_token_address, and each
+// currency of a quote map becomes a group of columns, e.g. quote_USD_price.
+// Columns follow the field order of the API types with quote currencies sorted
+// alphabetically, so the order is stable between runs.
+//
+//	list, _ := cmc.GetCurrencyListingsLatestAll()
+//	table, _ := export.Listings(list).Select("id", "symbol", "quote_*_price")
+//	export.WriteCSV(os.Stdout, table)
+package export
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+)
+
+// Table is a flattened view of API results.
+type Table struct {
+	Columns []string
+	Rows    []map[string]interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Listings returns a table with a row per listing.
+func Listings(list []cmcproapi.CurrencyListing) *Table {
+	return flatten(reflect.ValueOf(list))
+}
+
+// Quotes returns a table with a row per cryptocurrency of quotes ordered by rank.
+func Quotes(quotes cmcproapi.CurrencyListingMap) *Table {
+	list := make([]cmcproapi.CurrencyListing, 0, len(quotes))
+	for _, l := range quotes {
+		list = append(list, l)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CmcRank != list[j].CmcRank {
+			return list[i].CmcRank < list[j].CmcRank
+		}
+		return list[i].Id < list[j].Id
+	})
+	return Listings(list)
+}
+
+// GlobalMetrics returns a table with a row per snapshot of global metrics.
+func GlobalMetrics(metrics ...cmcproapi.GlobalMetrics) *Table {
+	return flatten(reflect.ValueOf(metrics))
+}
+
+// Select returns a table with the columns in the given order. Names may
+// contain path.Match patterns, e.g. "quote_*_price".
+func (t *Table) Select(columns ...string) (*Table, error) {
+	var selected []string
+	for _, pattern := range columns {
+		found := false
+		for _, col := range t.Columns {
+			if ok, err := path.Match(pattern, col); err != nil {
+				return nil, err
+			} else if ok {
+				selected = append(selected, col)
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.New("export: unknown column " + pattern)
+		}
+	}
+	return &Table{Columns: selected, Rows: t.Rows}, nil
+}
+
+// flatten returns a table of slice of structs.
+func flatten(slice reflect.Value) *Table {
+	typ := slice.Type().Elem()
+	keys := map[string]bool{}
+	for i := 0; i < slice.Len(); i++ {
+		mapKeys("", slice.Index(i), keys)
+	}
+	t := &Table{Columns: columns("", typ, keys)}
+	for i := 0; i < slice.Len(); i++ {
+		row := map[string]interface{}{}
+		values("", slice.Index(i), row)
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// fieldName returns the JSON name of the struct field.
+func fieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" {
+		name = f.Name
+	}
+	return name
+}
+
+// skipField reports whether the field is omitted from JSON.
+func skipField(f reflect.StructField) bool {
+	return f.Tag.Get("json") == "-"
+}
+
+// isTime reports whether t is time.Time or a type defined over it.
+func isTime(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.ConvertibleTo(timeType)
+}
+
+// mapKeys collects prefixed keys of quote maps of v.
+func mapKeys(prefix string, v reflect.Value, keys map[string]bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			mapKeys(prefix, v.Elem(), keys)
+		}
+	case reflect.Struct:
+		if isTime(v.Type()) {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if skipField(v.Type().Field(i)) {
+				continue
+			}
+			mapKeys(prefix+fieldName(v.Type().Field(i))+"_", v.Field(i), keys)
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			keys[prefix+k.String()] = true
+		}
+	}
+}
+
+// columns returns column names of type t, maps are expanded to the collected keys.
+func columns(prefix string, t reflect.Type, keys map[string]bool) (cols []string) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return columns(prefix, t.Elem(), keys)
+	case reflect.Struct:
+		if !isTime(t) {
+			for i := 0; i < t.NumField(); i++ {
+				if skipField(t.Field(i)) {
+					continue
+				}
+				cols = append(cols, columns(prefix+fieldName(t.Field(i))+"_", t.Field(i).Type, keys)...)
+			}
+			return
+		}
+	case reflect.Map:
+		var names []string
+		for k := range keys {
+			if strings.HasPrefix(k, prefix) && !strings.Contains(k[len(prefix):], "_") {
+				names = append(names, k[len(prefix):])
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			cols = append(cols, columns(prefix+name+"_", t.Elem(), keys)...)
+		}
+		return
+	}
+	return []string{strings.TrimSuffix(prefix, "_")}
+}
+
+// values fills row with the flattened values of v.
+func values(prefix string, v reflect.Value, row map[string]interface{}) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			values(prefix, v.Elem(), row)
+		}
+		return
+	case reflect.Struct:
+		if !isTime(v.Type()) {
+			for i := 0; i < v.NumField(); i++ {
+				if skipField(v.Type().Field(i)) {
+					continue
+				}
+				values(prefix+fieldName(v.Type().Field(i))+"_", v.Field(i), row)
+			}
+			return
+		}
+		if t := v.Convert(timeType).Interface().(time.Time); !t.IsZero() {
+			row[strings.TrimSuffix(prefix, "_")] = t
+		}
+		return
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			values(prefix+k.String()+"_", v.MapIndex(k), row)
+		}
+		return
+	case reflect.Slice:
+		if v.Len() != 0 {
+			row[strings.TrimSuffix(prefix, "_")] = v.Interface()
+		}
+		return
+	}
+	row[strings.TrimSuffix(prefix, "_")] = v.Interface()
+}
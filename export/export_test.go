@@ -0,0 +1,79 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package export
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+func newTestClient(t *testing.T) (*cmcproapi.Client, func()) {
+	srv := cmcproapitest.NewServer()
+	cmc, err := cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, cmcproapi.ApiVersion,
+		&http.Client{}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cmc, srv.Close
+}
+
+func TestListingsCSV(t *testing.T) {
+	cmc, done := newTestClient(t)
+	defer done()
+	list, err := cmc.GetCurrencyListingsLatestAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := Listings(list)
+	for _, col := range []string{"platform_token_address", "quote_BTC_price", "quote_USD_last_updated"} {
+		found := false
+		for _, c := range table.Columns {
+			found = found || c == col
+		}
+		if !found {
+			t.Errorf("missing column %s in %v", col, table.Columns)
+		}
+	}
+	table, err = table.Select("symbol", "quote_*_price", "tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = WriteCSV(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if lines[0] != "symbol,quote_BTC_price,quote_USD_price,tags" ||
+		lines[1] != "BTC,1,9558.55163723,mineable" {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+	if _, err = table.Select("unknown"); err == nil {
+		t.Error("expected unknown column error")
+	}
+}
+
+func TestGlobalMetricsNDJSON(t *testing.T) {
+	cmc, done := newTestClient(t)
+	defer done()
+	gm, err := cmc.GetGlobalQuotesLatestBySymbol("USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, _ := GlobalMetrics(gm).Select("active_cryptocurrencies", "last_updated")
+	var buf bytes.Buffer
+	if err = WriteNDJSON(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got !=
+		"{\"active_cryptocurrencies\":5,\"last_updated\":\"2019-08-30T18:51:28Z\"}\n" {
+		t.Errorf("unexpected NDJSON: %s", got)
+	}
+}
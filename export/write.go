@@ -0,0 +1,83 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteCSV writes the table with a header row. Missing values are empty,
+// lists are joined by semicolon.
+func WriteCSV(w io.Writer, t *Table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, col := range t.Columns {
+			record[i] = format(row[col])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteNDJSON writes the table as JSON object per line keeping column order.
+// Missing values are null.
+func WriteNDJSON(w io.Writer, t *Table) error {
+	bw := bufio.NewWriter(w)
+	for _, row := range t.Rows {
+		bw.WriteByte('{')
+		for i, col := range t.Columns {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			key, _ := json.Marshal(col)
+			v := row[col]
+			if tm, ok := v.(time.Time); ok {
+				v = tm.Format(time.RFC3339)
+			}
+			value, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			bw.Write(key)
+			bw.WriteByte(':')
+			bw.Write(value)
+		}
+		bw.WriteString("}\n")
+	}
+	return bw.Flush()
+}
+
+// format returns the CSV representation of a value.
+func format(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(val)
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case []string:
+		return strings.Join(val, ";")
+	}
+	return fmt.Sprint(v)
+}
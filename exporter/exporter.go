@@ -0,0 +1,191 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+// Package exporter publishes market data and client metrics in the
+// Prometheus text exposition format.
+//
+// Market gauges are updated from poller ticks, client metrics are collected
+// by the instrumented transport of the Client's http.Client:
+//
+//	e := exporter.New()
+//	cmc, _ := cmcproapi.NewCustom(apiKey, cmcproapi.ApiDomain, cmcproapi.ApiVersion,
+//		&http.Client{Transport: e.Transport(nil)}, time.Minute)
+//	p := poller.New(cmc, poller.Options{Global: true})
+//	p.Watch(1, 1027)
+//	e.Attach(p)
+//	p.Start(ctx)
+//	http.Handle("/metrics", e)
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/poller"
+)
+
+// DefaultBuckets of the request latency histogram in seconds.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Exporter is an http.Handler serving collected metrics.
+type Exporter struct {
+	price         *metric
+	marketCap     *metric
+	volume        *metric
+	percentChange *metric
+	globalCap     *metric
+	globalVolume  *metric
+	dominance     *metric
+	activeCount   *metric
+	lastUpdated   *metric
+	requests      *metric
+	latency       *metric
+	errors        *metric
+	credits       *metric
+	pollErrors    *metric
+
+	metrics []*metric
+}
+
+// New returns an instantiated Exporter.
+func New() *Exporter {
+	e := &Exporter{
+		price: newMetric("gauge", "cmc_price",
+			"Latest price of the cryptocurrency.", "id", "symbol", "convert"),
+		marketCap: newMetric("gauge", "cmc_market_cap",
+			"Market cap of the cryptocurrency.", "id", "symbol", "convert"),
+		volume: newMetric("gauge", "cmc_volume_24h",
+			"Rolling 24 hour volume of the cryptocurrency.", "id", "symbol", "convert"),
+		percentChange: newMetric("gauge", "cmc_percent_change",
+			"Price change of the cryptocurrency in percents.", "id", "symbol", "convert", "period"),
+		globalCap: newMetric("gauge", "cmc_global_total_market_cap",
+			"Total market cap of all cryptocurrencies.", "convert"),
+		globalVolume: newMetric("gauge", "cmc_global_total_volume_24h",
+			"Total rolling 24 hour volume of all cryptocurrencies.", "convert"),
+		dominance: newMetric("gauge", "cmc_global_dominance",
+			"Market cap dominance in percents.", "symbol"),
+		activeCount: newMetric("gauge", "cmc_global_active_cryptocurrencies",
+			"Number of active cryptocurrencies."),
+		lastUpdated: newMetric("gauge", "cmc_last_updated_timestamp_seconds",
+			"Time the quote was last updated by CoinMarketCap.", "id", "symbol"),
+		requests: newMetric("counter", "cmc_client_requests_total",
+			"API requests by endpoint and HTTP status.", "endpoint", "code"),
+		latency: newHistogram("cmc_client_request_duration_seconds",
+			"API request latency.", DefaultBuckets, "endpoint"),
+		errors: newMetric("counter", "cmc_client_errors_total",
+			"API responses with non-zero error code.", "endpoint", "error_code"),
+		credits: newMetric("counter", "cmc_client_credits_total",
+			"API credits consumed.", "endpoint"),
+		pollErrors: newMetric("counter", "cmc_poll_errors_total",
+			"Failed polls."),
+	}
+	e.metrics = []*metric{e.price, e.marketCap, e.volume, e.percentChange, e.lastUpdated,
+		e.globalCap, e.globalVolume, e.dominance, e.activeCount,
+		e.requests, e.latency, e.errors, e.credits, e.pollErrors}
+	return e
+}
+
+// ServeHTTP writes all metrics.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	for _, m := range e.metrics {
+		m.write(w)
+	}
+}
+
+// Attach updates market gauges on every tick of the poller.
+func (e *Exporter) Attach(p *poller.Poller) {
+	p.OnTick(e.Observe)
+}
+
+// Observe updates market gauges from the tick.
+func (e *Exporter) Observe(tick poller.Tick) {
+	if tick.Err != nil {
+		e.pollErrors.add(1)
+	}
+	for _, u := range tick.Updates {
+		c := u.Current
+		id := strconv.Itoa(c.Id)
+		if t := time.Time(c.LastUpdated); !t.IsZero() {
+			e.lastUpdated.set(float64(t.Unix()), id, c.Symbol)
+		}
+		if c.Quote == nil {
+			continue
+		}
+		for convert, q := range *c.Quote {
+			e.price.set(q.Price, id, c.Symbol, convert)
+			e.marketCap.set(q.MarketCap, id, c.Symbol, convert)
+			e.volume.set(q.Volume24h, id, c.Symbol, convert)
+			e.percentChange.set(q.PercentChange1h, id, c.Symbol, convert, "1h")
+			e.percentChange.set(q.PercentChange24h, id, c.Symbol, convert, "24h")
+			e.percentChange.set(q.PercentChange7d, id, c.Symbol, convert, "7d")
+		}
+	}
+	if g := tick.Global; g != nil {
+		e.dominance.set(g.BtcDominance, "BTC")
+		e.dominance.set(g.EthDominance, "ETH")
+		e.activeCount.set(float64(g.ActiveCryptocurrencies))
+		if g.Quote != nil {
+			for convert, q := range *g.Quote {
+				e.globalCap.set(q.TotalMarketCap, convert)
+				e.globalVolume.set(q.TotalVolume24h, convert)
+			}
+		}
+	}
+}
+
+// Transport returns base instrumented with client metrics, http.DefaultTransport
+// is used if base is nil.
+func (e *Exporter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{exporter: e, base: base}
+}
+
+type transport struct {
+	exporter *Exporter
+	base     http.RoundTripper
+}
+
+var reEndpoint = regexp.MustCompile(`/v\d+/(.+)$`)
+
+// RoundTrip records the request and the status object of the response.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	e := t.exporter
+	endpoint := req.URL.Path
+	if m := reEndpoint.FindStringSubmatch(endpoint); m != nil {
+		endpoint = m[1]
+	}
+	started := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	e.latency.observe(time.Since(started).Seconds(), endpoint)
+	if err != nil {
+		e.requests.add(1, endpoint, "error")
+		return nil, err
+	}
+	e.requests.add(1, endpoint, strconv.Itoa(resp.StatusCode))
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	var r cmcproapi.Response
+	if json.Unmarshal(body, &r) == nil {
+		if r.Status.CreditCount != 0 {
+			e.credits.add(float64(r.Status.CreditCount), endpoint)
+		}
+		if r.Status.ErrorCode != 0 {
+			e.errors.add(1, endpoint, strconv.Itoa(r.Status.ErrorCode))
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,52 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package exporter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+	"github.com/nikchis/cmc-proapi/poller"
+)
+
+func TestExporter(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	e := New()
+	cmc, _ := cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, cmcproapi.ApiVersion,
+		&http.Client{Transport: e.Transport(nil)}, time.Second)
+	p := poller.New(cmc, poller.Options{Global: true, Convert: []string{"USD", "EUR"}})
+	p.Watch(1, 1027)
+	e.Observe(p.Poll(context.Background()))
+	srv.SetFault("cryptocurrency/info", cmcproapitest.Fault{
+		ErrorCode: cmcproapitest.ErrorCodeMinuteRateLimit})
+	cmc.GetCurrencyInfoById("1")
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`cmc_price{id="1",symbol="BTC",convert="USD"} 9558.55163723`,
+		`cmc_percent_change{id="1027",symbol="ETH",convert="EUR",period="24h"} -0.826099`,
+		`cmc_global_dominance{symbol="BTC"}`,
+		`cmc_global_total_market_cap{convert="EUR"}`,
+		`cmc_client_requests_total{endpoint="cryptocurrency/quotes/latest",code="200"} 1`,
+		`cmc_client_requests_total{endpoint="cryptocurrency/info",code="429"} 1`,
+		`cmc_client_errors_total{endpoint="cryptocurrency/info",error_code="1008"} 1`,
+		`cmc_client_credits_total{endpoint="cryptocurrency/quotes/latest"} 2`,
+		`cmc_client_request_duration_seconds_count{endpoint="global-metrics/quotes/latest"} 1`,
+		`# TYPE cmc_client_request_duration_seconds histogram`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %s in:\n%s", want, body)
+		}
+	}
+}
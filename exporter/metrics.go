@@ -0,0 +1,143 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// metric is a family of series sharing name, type and label names,
+// written in the Prometheus text exposition format.
+type metric struct {
+	name    string
+	help    string
+	kind    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labels []string
+	value  float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newMetric(kind, name, help string, labels ...string) *metric {
+	return &metric{name: name, help: help, kind: kind, labels: labels, series: map[string]*series{}}
+}
+
+func newHistogram(name, help string, buckets []float64, labels ...string) *metric {
+	m := newMetric("histogram", name, help, labels...)
+	m.buckets = buckets
+	return m
+}
+
+// get returns series by label values, creating it if needed.
+func (m *metric) get(values []string) *series {
+	key := strings.Join(values, "\xff")
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{labels: values}
+		if m.buckets != nil {
+			s.counts = make([]uint64, len(m.buckets))
+		}
+		m.series[key] = s
+	}
+	return s
+}
+
+// set sets gauge value.
+func (m *metric) set(v float64, values ...string) {
+	m.mu.Lock()
+	m.get(values).value = v
+	m.mu.Unlock()
+}
+
+// add increments counter value.
+func (m *metric) add(v float64, values ...string) {
+	m.mu.Lock()
+	m.get(values).value += v
+	m.mu.Unlock()
+}
+
+// observe records histogram sample.
+func (m *metric) observe(v float64, values ...string) {
+	m.mu.Lock()
+	s := m.get(values)
+	for i, b := range m.buckets {
+		if v <= b {
+			s.counts[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+	m.mu.Unlock()
+}
+
+// write prints the metric family, series are sorted by labels.
+func (m *metric) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.series) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+	keys := make([]string, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := m.series[k]
+		if m.kind != "histogram" {
+			fmt.Fprintf(w, "%s%s %s\n", m.name, labels(m.labels, s.labels), formatFloat(s.value))
+			continue
+		}
+		names := append(append([]string(nil), m.labels...), "le")
+		values := append(append([]string(nil), s.labels...), "")
+		for i, b := range m.buckets {
+			values[len(values)-1] = formatFloat(b)
+			fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, labels(names, values), s.counts[i])
+		}
+		values[len(values)-1] = "+Inf"
+		fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, labels(names, values), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", m.name, labels(m.labels, s.labels), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", m.name, labels(m.labels, s.labels), s.count)
+	}
+}
+
+// labels formats label pairs, e.g. {symbol="BTC",convert="USD"}.
+func labels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, n := range names {
+		v := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(values[i])
+		pairs[i] = n + `="` + v + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -5,6 +5,7 @@
 package cmcproapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 )
@@ -19,6 +20,9 @@ type GlobalMetrics struct {
 	TotalExchanges         int             `json:"total_exchanges"`
 	LastUpdated            jsonTime        `json:"last_updated"`
 	Quote                  *GlobalQuoteMap `json:"quote"`
+
+	// set on results of the sandbox environment
+	Sandbox bool `json:"sandbox,omitempty"`
 }
 
 type GlobalQuoteMap map[string]GlobalQuote
@@ -44,7 +48,7 @@ func (c *Client) GetGlobalQuotesLatestById(convert_id string) (result GlobalMetr
 	if raw, err = c.handleRequest(ltUriGlobalQuotesLatest, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
 	return
 }
 
@@ -57,6 +61,23 @@ func (c *Client) GetGlobalQuotesLatestBySymbol(convert string) (result GlobalMet
 	if raw, err = c.handleRequest(ltUriGlobalQuotesLatest, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
+	return
+}
+
+// GetGlobalQuotesLatestByQuery acts identically to GetGlobalQuotesLatestById, except that it
+// uses typed query which is validated before the request is sent, and ctx which
+// cancels the request.
+func (c *Client) GetGlobalQuotesLatestByQuery(
+	ctx context.Context, query GlobalQuotesQuery) (result GlobalMetrics, err error) {
+	var raw json.RawMessage
+	var q url.Values
+	if q, err = query.Values(); err != nil {
+		return
+	}
+	if raw, err = c.handleRequest(ltUriGlobalQuotesLatest, &q, ctx); err != nil {
+		return
+	}
+	err = c.unmarshal(raw, &result)
 	return
 }
@@ -0,0 +1,202 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+)
+
+// FileStore is a Storage keeping each series in an append-only file of
+// newline-delimited JSON records in a directory.
+type FileStore struct {
+	dir string
+
+	mu   sync.Mutex
+	seen map[string]map[int64]bool
+}
+
+// record is a line of a series file.
+type record struct {
+	Time     time.Time                `json:"t"`
+	Currency *cmcproapi.CurrencyQuote `json:"c,omitempty"`
+	Global   *cmcproapi.GlobalQuote   `json:"g,omitempty"`
+	Sandbox  bool                     `json:"s,omitempty"`
+}
+
+// Open returns a FileStore in dir, creating the directory if needed.
+func Open(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir, seen: map[string]map[int64]bool{}}, nil
+}
+
+// path returns the file of the series, e.g. 1_USD.ndjson or global_EUR.ndjson,
+// prefixed by sandbox_ for a series of the sandbox environment.
+func (fs *FileStore) path(id int, convert string, sandbox bool) string {
+	name := strconv.Itoa(id)
+	if id == GlobalId {
+		name = "global"
+	}
+	if sandbox {
+		name = "sandbox_" + name
+	}
+	return filepath.Join(fs.dir, name+"_"+url.PathEscape(convert)+".ndjson")
+}
+
+// Put appends new samples to their series files. Samples of the sandbox
+// environment are kept in series of their own, read by SandboxRange.
+func (fs *FileStore) Put(samples ...Sample) (n int, err error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	lines := map[string]*bytes.Buffer{}
+	var order []string
+	for _, s := range samples {
+		path := fs.path(s.Id, s.Convert, s.Sandbox)
+		seen, ok := fs.seen[path]
+		if !ok {
+			if seen, err = load(path); err != nil {
+				return
+			}
+			fs.seen[path] = seen
+		}
+		key := s.Time.UnixNano()
+		if seen[key] {
+			continue
+		}
+		data, err := json.Marshal(&record{
+			Time: s.Time, Currency: s.Currency, Global: s.Global, Sandbox: s.Sandbox})
+		if err != nil {
+			return n, err
+		}
+		buf, ok := lines[path]
+		if !ok {
+			buf = &bytes.Buffer{}
+			lines[path] = buf
+			order = append(order, path)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+		seen[key] = true
+		n++
+	}
+	for _, path := range order {
+		if err = appendFile(path, lines[path].Bytes()); err != nil {
+			// let the next Put reload what has actually been written
+			delete(fs.seen, path)
+			return
+		}
+	}
+	return
+}
+
+// Range reads samples of the series within [from, to).
+func (fs *FileStore) Range(id int, convert string, from, to time.Time) ([]Sample, error) {
+	return fs.readRange(id, convert, false, from, to)
+}
+
+// SandboxRange reads samples of the sandbox series within [from, to).
+func (fs *FileStore) SandboxRange(id int, convert string, from, to time.Time) ([]Sample, error) {
+	return fs.readRange(id, convert, true, from, to)
+}
+
+// readRange reads samples of the series within [from, to), skipping records
+// of the other environment.
+func (fs *FileStore) readRange(
+	id int, convert string, sandbox bool, from, to time.Time) (samples []Sample, err error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	err = scan(fs.path(id, convert, sandbox), func(r record) {
+		if r.Sandbox != sandbox ||
+			(!from.IsZero() && r.Time.Before(from)) || (!to.IsZero() && !r.Time.Before(to)) {
+			return
+		}
+		samples = append(samples, Sample{
+			Id: id, Convert: convert, Time: r.Time, Currency: r.Currency, Global: r.Global,
+			Sandbox: r.Sandbox})
+	})
+	if _, ok := err.(partialLine); ok {
+		err = nil
+	}
+	sortSamples(samples)
+	return
+}
+
+// load returns times of samples stored in the file. A trailing partial line
+// left by an interrupted write is truncated.
+func load(path string) (seen map[int64]bool, err error) {
+	seen = map[int64]bool{}
+	err = scan(path, func(r record) {
+		seen[r.Time.UnixNano()] = true
+	})
+	if size, ok := err.(partialLine); ok {
+		err = os.Truncate(path, int64(size))
+	}
+	return
+}
+
+// partialLine is returned by scan for a file not ending with a newline,
+// the value is the size of its complete lines.
+type partialLine int
+
+func (p partialLine) Error() string {
+	return "history: partial line at offset " + strconv.Itoa(int(p))
+}
+
+// scan decodes records of the file, a missing file is empty.
+func scan(path string, fn func(record)) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	rd := bufio.NewReader(f)
+	offset := 0
+	for line := 1; ; line++ {
+		data, err := rd.ReadBytes('\n')
+		if err == io.EOF {
+			if len(data) > 0 {
+				return partialLine(offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		offset += len(data)
+		var r record
+		if err := json.Unmarshal(data, &r); err != nil {
+			return fmt.Errorf("history: %s:%d: %v", path, line, err)
+		}
+		fn(r)
+	}
+}
+
+func appendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
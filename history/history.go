@@ -0,0 +1,167 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+// Package history keeps quotes fetched by the client so that price history
+// can be built without the paid historical endpoints.
+//
+//	s, _ := history.Open("quotes")
+//	history.Attach(p, s, nil)
+//	p.Start(ctx)
+//	...
+//	samples, _ := s.Range(1, "USD", time.Now().Add(-24*time.Hour), time.Time{})
+//	candles := history.Downsample(samples, time.Hour, nil)
+package history
+
+import (
+	"sort"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/poller"
+)
+
+// GlobalId is the series id of global metrics.
+const GlobalId = 0
+
+// Sample is a quote snapshot of a cryptocurrency or of global metrics in the
+// Convert currency. Time is the LastUpdated time of the quote. Exactly one of
+// Currency and Global is set. Sandbox marks mock data of the sandbox
+// environment.
+type Sample struct {
+	Id       int
+	Convert  string
+	Time     time.Time
+	Currency *cmcproapi.CurrencyQuote
+	Global   *cmcproapi.GlobalQuote
+	Sandbox  bool
+}
+
+// Value returns the price of a cryptocurrency or the total market cap.
+func (s Sample) Value() float64 {
+	switch {
+	case s.Currency != nil:
+		return s.Currency.Price
+	case s.Global != nil:
+		return s.Global.TotalMarketCap
+	}
+	return 0
+}
+
+// Storage keeps samples keyed by id, convert currency and time.
+type Storage interface {
+	// Put stores samples and returns how many of them were new. Samples with
+	// the time of an already stored sample of the same series are skipped.
+	Put(samples ...Sample) (int, error)
+	// Range returns samples of the series within [from, to) sorted by time,
+	// a zero bound is open. Samples of the sandbox environment are never
+	// returned, implementations store them apart or reject them.
+	Range(id int, convert string, from, to time.Time) ([]Sample, error)
+}
+
+// CurrencySamples returns a sample for each quote of the listing.
+func CurrencySamples(l cmcproapi.CurrencyListing) (samples []Sample) {
+	if l.Quote == nil {
+		return
+	}
+	for convert, q := range *l.Quote {
+		q := q
+		t := time.Time(q.LastUpdated)
+		if t.IsZero() {
+			t = time.Time(l.LastUpdated)
+		}
+		samples = append(samples, Sample{Id: l.Id, Convert: convert, Time: t, Currency: &q})
+	}
+	sortSamples(samples)
+	return
+}
+
+// GlobalSamples returns a sample for each quote of the global metrics.
+func GlobalSamples(g cmcproapi.GlobalMetrics) (samples []Sample) {
+	if g.Quote == nil {
+		return
+	}
+	for convert, q := range *g.Quote {
+		q := q
+		t := time.Time(q.LastUpdated)
+		if t.IsZero() {
+			t = time.Time(g.LastUpdated)
+		}
+		samples = append(samples, Sample{Id: GlobalId, Convert: convert, Time: t, Global: &q})
+	}
+	sortSamples(samples)
+	return
+}
+
+// Record stores quotes delivered with the tick.
+func Record(s Storage, tick poller.Tick) (int, error) {
+	var samples []Sample
+	for _, u := range tick.Updates {
+		samples = append(samples, CurrencySamples(u.Current)...)
+	}
+	if tick.Global != nil {
+		samples = append(samples, GlobalSamples(*tick.Global)...)
+	}
+	if len(samples) == 0 {
+		return 0, nil
+	}
+	for i := range samples {
+		samples[i].Sandbox = tick.Sandbox
+	}
+	return s.Put(samples...)
+}
+
+// Attach records every tick of the poller, onError is called if storing fails.
+func Attach(p *poller.Poller, s Storage, onError func(error)) {
+	p.OnTick(func(tick poller.Tick) {
+		if _, err := Record(s, tick); err != nil && onError != nil {
+			onError(err)
+		}
+	})
+}
+
+// Candle is an OHLC bucket starting at Time.
+type Candle struct {
+	Time    time.Time
+	Open    float64
+	High    float64
+	Low     float64
+	Close   float64
+	Samples int
+}
+
+// Downsample aggregates samples sorted by time into candles of the interval.
+// Buckets are aligned to multiples of the interval since the zero time, empty
+// buckets are omitted. Sample.Value is used if value is nil.
+func Downsample(samples []Sample, interval time.Duration, value func(Sample) float64) (candles []Candle) {
+	if value == nil {
+		value = Sample.Value
+	}
+	for _, s := range samples {
+		t := s.Time.Truncate(interval)
+		v := value(s)
+		if n := len(candles); n > 0 && candles[n-1].Time.Equal(t) {
+			c := &candles[n-1]
+			if v > c.High {
+				c.High = v
+			}
+			if v < c.Low {
+				c.Low = v
+			}
+			c.Close = v
+			c.Samples++
+			continue
+		}
+		candles = append(candles, Candle{Time: t, Open: v, High: v, Low: v, Close: v, Samples: 1})
+	}
+	return
+}
+
+func sortSamples(samples []Sample) {
+	sort.SliceStable(samples, func(i, j int) bool {
+		if !samples[i].Time.Equal(samples[j].Time) {
+			return samples[i].Time.Before(samples[j].Time)
+		}
+		return samples[i].Convert < samples[j].Convert
+	})
+}
@@ -0,0 +1,166 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package history
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+	"github.com/nikchis/cmc-proapi/poller"
+)
+
+var t0 = time.Date(2019, 8, 30, 18, 0, 0, 0, time.UTC)
+
+func price(id int, minutes int, p float64) Sample {
+	return Sample{Id: id, Convert: "USD", Time: t0.Add(time.Duration(minutes) * time.Minute),
+		Currency: &cmcproapi.CurrencyQuote{Price: p}}
+}
+
+// tempStore returns a store in a new temporary directory.
+func tempStore(t *testing.T) (*FileStore, string) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, dir
+}
+
+func TestFileStore(t *testing.T) {
+	s, dir := tempStore(t)
+	defer os.RemoveAll(dir)
+	n, err := s.Put(price(1, 2, 102), price(1, 0, 100), price(1, 1, 101), price(1027, 0, 200))
+	if err != nil || n != 4 {
+		t.Fatalf("Put() = %d, %v", n, err)
+	}
+	if n, _ = s.Put(price(1, 1, 101), price(1, 3, 103)); n != 1 {
+		t.Errorf("Put() stored %d duplicates", 2-n)
+	}
+
+	// dedupe survives reopening
+	s, _ = Open(dir)
+	if n, _ = s.Put(price(1, 0, 100)); n != 0 {
+		t.Error("Put() stored a duplicate after reopen")
+	}
+	samples, err := s.Range(1, "USD", t0.Add(time.Minute), t0.Add(3*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 || samples[0].Value() != 101 || samples[1].Value() != 102 {
+		t.Errorf("Range() = %+v", samples)
+	}
+	if samples, _ = s.Range(1, "USD", time.Time{}, time.Time{}); len(samples) != 4 {
+		t.Errorf("open Range() returned %d samples", len(samples))
+	}
+	if samples, _ = s.Range(1, "EUR", time.Time{}, time.Time{}); len(samples) != 0 {
+		t.Errorf("Range() of missing series returned %d samples", len(samples))
+	}
+}
+
+func TestPartialLine(t *testing.T) {
+	s, dir := tempStore(t)
+	defer os.RemoveAll(dir)
+	s.Put(price(1, 0, 100))
+	path := filepath.Join(dir, "1_USD.ndjson")
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"t":"2019-08-30T18:01:00Z","c":{"pri`)
+	f.Close()
+
+	s, _ = Open(dir)
+	if samples, err := s.Range(1, "USD", time.Time{}, time.Time{}); err != nil || len(samples) != 1 {
+		t.Fatalf("Range() = %d samples, %v", len(samples), err)
+	}
+	if n, err := s.Put(price(1, 1, 101)); err != nil || n != 1 {
+		t.Fatalf("Put() = %d, %v", n, err)
+	}
+	if samples, err := s.Range(1, "USD", time.Time{}, time.Time{}); err != nil || len(samples) != 2 {
+		t.Errorf("Range() = %d samples, %v", len(samples), err)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	samples := []Sample{
+		price(1, 0, 100), price(1, 20, 110), price(1, 40, 90), price(1, 59, 105),
+		price(1, 60, 105), price(1, 180, 120),
+	}
+	candles := Downsample(samples, time.Hour, nil)
+	want := []Candle{
+		{Time: t0, Open: 100, High: 110, Low: 90, Close: 105, Samples: 4},
+		{Time: t0.Add(time.Hour), Open: 105, High: 105, Low: 105, Close: 105, Samples: 1},
+		{Time: t0.Add(3 * time.Hour), Open: 120, High: 120, Low: 120, Close: 120, Samples: 1},
+	}
+	if len(candles) != len(want) {
+		t.Fatalf("Downsample() = %+v", candles)
+	}
+	for i := range want {
+		if !candles[i].Time.Equal(want[i].Time) || candles[i].Open != want[i].Open ||
+			candles[i].High != want[i].High || candles[i].Low != want[i].Low ||
+			candles[i].Close != want[i].Close || candles[i].Samples != want[i].Samples {
+			t.Errorf("candle %d = %+v, want %+v", i, candles[i], want[i])
+		}
+	}
+}
+
+func TestAttach(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	cmc, _ := cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, cmcproapi.ApiVersion,
+		&http.Client{}, time.Second)
+	p := poller.New(cmc, poller.Options{Global: true, Convert: []string{"USD", "EUR"}})
+	p.Watch(1)
+	s, dir := tempStore(t)
+	defer os.RemoveAll(dir)
+
+	tick := p.Poll(context.Background())
+	if n, err := Record(s, tick); err != nil || n != 4 {
+		t.Fatalf("Record() = %d, %v", n, err)
+	}
+	if n, _ := Record(s, tick); n != 0 {
+		t.Errorf("Record() stored %d duplicates", n)
+	}
+	samples, _ := s.Range(1, "EUR", time.Time{}, time.Time{})
+	if len(samples) != 1 || samples[0].Currency == nil || samples[0].Time.IsZero() {
+		t.Errorf("Range() = %+v", samples)
+	}
+	samples, _ = s.Range(GlobalId, "USD", time.Time{}, time.Time{})
+	if len(samples) != 1 || samples[0].Global == nil || samples[0].Value() == 0 {
+		t.Errorf("global Range() = %+v", samples)
+	}
+}
+
+func TestRecordSandbox(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	cmc, _ := cmcproapi.NewCustom("", srv.URL, cmcproapi.Sandbox)
+	p := poller.New(cmc, poller.Options{Convert: []string{"USD"}})
+	p.Watch(1)
+	s, dir := tempStore(t)
+	defer os.RemoveAll(dir)
+
+	tick := p.Poll(context.Background())
+	if !tick.Sandbox {
+		t.Error("expected sandbox tick")
+	}
+	if _, err := Record(s, tick); err != nil {
+		t.Fatal(err)
+	}
+	if samples, _ := s.Range(1, "USD", time.Time{}, time.Time{}); len(samples) != 0 {
+		t.Errorf("sandbox samples in production series %+v", samples)
+	}
+	samples, _ := s.SandboxRange(1, "USD", time.Time{}, time.Time{})
+	if len(samples) != 1 || !samples[0].Sandbox {
+		t.Errorf("SandboxRange() = %+v", samples)
+	}
+}
@@ -0,0 +1,108 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ltRedacted replaces the API key in headers passed to hooks.
+const ltRedacted = "REDACTED"
+
+// RequestInfo describes a request sent to the API. The API key header is
+// always redacted. Attempt counts requests failed over to another key of a
+// KeyPool, starting from 1.
+type RequestInfo struct {
+	Method   string
+	Version  string
+	Endpoint string
+	Query    url.Values
+	Header   http.Header
+	Attempt  int
+}
+
+// ResponseInfo describes the outcome of a request. HTTPStatus is zero and
+// Status is empty if no response was received. Err is set on transport
+// failures and on responses with a non-zero error code.
+type ResponseInfo struct {
+	HTTPStatus int
+	Status     ResponseStatus
+	Elapsed    time.Duration
+	Err        error
+}
+
+// Hook observes requests sent to the API, pass it to NewCustom.
+//
+// Hooks are called for every upstream request, responses served from the
+// Cache or shared with a concurrent identical request do not reach the API
+// and are not reported. The context is the one of the caller which started
+// the request.
+type Hook interface {
+	BeforeRequest(ctx context.Context, req *RequestInfo)
+	AfterResponse(ctx context.Context, req *RequestInfo, resp *ResponseInfo)
+}
+
+// HookFuncs adapts functions to Hook, nil functions are skipped.
+type HookFuncs struct {
+	Before func(ctx context.Context, req *RequestInfo)
+	After  func(ctx context.Context, req *RequestInfo, resp *ResponseInfo)
+}
+
+// BeforeRequest calls Before.
+func (h HookFuncs) BeforeRequest(ctx context.Context, req *RequestInfo) {
+	if h.Before != nil {
+		h.Before(ctx, req)
+	}
+}
+
+// AfterResponse calls After.
+func (h HookFuncs) AfterResponse(ctx context.Context, req *RequestInfo, resp *ResponseInfo) {
+	if h.After != nil {
+		h.After(ctx, req, resp)
+	}
+}
+
+// Logger is a structured logger with alternating key-value arguments,
+// *slog.Logger satisfies it. Pass it to NewCustom to log every request.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...interface{})
+	ErrorContext(ctx context.Context, msg string, args ...interface{})
+}
+
+// LogHook returns a Hook logging completed requests to the logger, failed
+// ones at error level and the rest at debug level.
+func LogHook(logger Logger) Hook {
+	return HookFuncs{After: func(ctx context.Context, req *RequestInfo, resp *ResponseInfo) {
+		args := []interface{}{
+			"endpoint", req.Endpoint,
+			"query", req.Query.Encode(),
+			"header", req.Header,
+			"http_status", resp.HTTPStatus,
+			"error_code", resp.Status.ErrorCode,
+			"credit_count", resp.Status.CreditCount,
+			"elapsed", resp.Elapsed,
+		}
+		if resp.Err != nil {
+			logger.ErrorContext(ctx, "cmc request failed", append(args, "error", resp.Err.Error())...)
+			return
+		}
+		logger.DebugContext(ctx, "cmc request", args...)
+	}}
+}
+
+// redactHeader returns a copy of header with the API key redacted.
+func redactHeader(header http.Header) http.Header {
+	h := make(http.Header, len(header))
+	for k, v := range header {
+		h[k] = append([]string(nil), v...)
+	}
+	if _, ok := h[http.CanonicalHeaderKey(ltCmcProApiKeyX)]; ok {
+		h.Set(ltCmcProApiKeyX, ltRedacted)
+	}
+	return h
+}
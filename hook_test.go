@@ -0,0 +1,87 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+// testLogger records messages with their arguments.
+type testLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testLogger) log(level, msg string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprint(level, " ", msg, " ", args))
+}
+
+func (l *testLogger) DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("DEBUG", msg, args)
+}
+
+func (l *testLogger) ErrorContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("ERROR", msg, args)
+}
+
+type ctxKey struct{}
+
+func TestHooks(t *testing.T) {
+	var before, after []string
+	var mu sync.Mutex
+	hook := HookFuncs{
+		Before: func(ctx context.Context, req *RequestInfo) {
+			mu.Lock()
+			before = append(before, fmt.Sprint(ctx.Value(ctxKey{}), " ", req.Endpoint))
+			mu.Unlock()
+		},
+		After: func(ctx context.Context, req *RequestInfo, resp *ResponseInfo) {
+			mu.Lock()
+			after = append(after, fmt.Sprintf("%s %d %d %v", req.Endpoint, resp.HTTPStatus,
+				resp.Status.CreditCount, resp.Err != nil))
+			mu.Unlock()
+		},
+	}
+	logger := &testLogger{}
+	cmc, _ := NewCustom(cmcproapitest.APIKey, testServer.URL, TestApiVersion,
+		&http.Client{}, time.Second, hook, logger)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "traced")
+	if _, err := cmc.GetCurrencyInfoByIds(ctx, []int{1, 1027}, BatchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	testServer.SetFault(EndpointKeyInfo, cmcproapitest.Fault{
+		ErrorCode: cmcproapitest.ErrorCodeMinuteRateLimit, Count: 1})
+	if _, err := cmc.GetKeyInfo(); err == nil {
+		t.Fatal("expected rate limit error")
+	}
+
+	if len(before) != 2 || before[0] != "traced cryptocurrency/info" {
+		t.Errorf("unexpected BeforeRequest calls: %q", before)
+	}
+	if len(after) != 2 || after[0] != "cryptocurrency/info 200 1 false" ||
+		after[1] != "key/info 429 0 true" {
+		t.Errorf("unexpected AfterResponse calls: %q", after)
+	}
+	if len(logger.lines) != 2 || !strings.HasPrefix(logger.lines[0], "DEBUG cmc request [endpoint cryptocurrency/info") ||
+		!strings.HasPrefix(logger.lines[1], "ERROR cmc request failed") {
+		t.Errorf("unexpected log: %q", logger.lines)
+	}
+	for _, line := range logger.lines {
+		if strings.Contains(line, cmcproapitest.APIKey) || !strings.Contains(line, ltRedacted) {
+			t.Errorf("API key is not redacted: %s", line)
+		}
+	}
+}
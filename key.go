@@ -0,0 +1,51 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"encoding/json"
+)
+
+type KeyInfo struct {
+	Plan  KeyPlan  `json:"plan"`
+	Usage KeyUsage `json:"usage"`
+
+	// set on results of the sandbox environment
+	Sandbox bool `json:"sandbox,omitempty"`
+}
+
+type KeyPlan struct {
+	CreditLimitDaily                 int      `json:"credit_limit_daily"`
+	CreditLimitDailyReset            string   `json:"credit_limit_daily_reset"`
+	CreditLimitDailyResetTimestamp   jsonTime `json:"credit_limit_daily_reset_timestamp"`
+	CreditLimitMonthly               int      `json:"credit_limit_monthly"`
+	CreditLimitMonthlyReset          string   `json:"credit_limit_monthly_reset"`
+	CreditLimitMonthlyResetTimestamp jsonTime `json:"credit_limit_monthly_reset_timestamp"`
+	RateLimitMinute                  int      `json:"rate_limit_minute"`
+}
+
+type KeyUsage struct {
+	CurrentMinute KeyUsagePeriod `json:"current_minute"`
+	CurrentDay    KeyUsagePeriod `json:"current_day"`
+	CurrentMonth  KeyUsagePeriod `json:"current_month"`
+}
+
+type KeyUsagePeriod struct {
+	RequestsMade int `json:"requests_made,omitempty"`
+	RequestsLeft int `json:"requests_left,omitempty"`
+	CreditsUsed  int `json:"credits_used,omitempty"`
+	CreditsLeft  int `json:"credits_left,omitempty"`
+}
+
+// GetKeyInfo returns API key details and usage stats. This endpoint does not
+// consume credits.
+func (c *Client) GetKeyInfo() (result KeyInfo, err error) {
+	var raw json.RawMessage
+	if raw, err = c.handleRequest(ltUriKeyInfo); err != nil {
+		return
+	}
+	err = c.unmarshal(raw, &result)
+	return
+}
@@ -0,0 +1,18 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"testing"
+)
+
+func TestGetKeyInfo(t *testing.T) {
+	cmc, err := NewTest()
+	_, err = cmc.GetKeyInfo()
+	if err != nil {
+		t.Error(err)
+		t.Fail()
+	}
+}
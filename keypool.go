@@ -0,0 +1,263 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// KeyStrategy selects the key of a KeyPool used for a request.
+type KeyStrategy int
+
+const (
+	// KeyRoundRobin uses keys in turn.
+	KeyRoundRobin KeyStrategy = iota
+	// KeyLeastUsed uses the key which consumed the fewest credits.
+	KeyLeastUsed
+	// KeyPriority uses the first key in the pool which is not benched.
+	KeyPriority
+)
+
+// Error codes of the status object which make Client fail over to another key.
+const (
+	errorCodeMinuteRateLimit  = 1008
+	errorCodeDailyRateLimit   = 1009
+	errorCodeMonthlyRateLimit = 1010
+)
+
+// KeyPool is a set of API keys, e.g. of several plans, shared by requests of
+// Client, pass it to NewCustom in place of the key:
+//
+//	pool := cmcproapi.NewKeyPool(cmcproapi.KeyPriority, paidKey, freeKey)
+//	cmc, _ := cmcproapi.NewCustom(pool, cmcproapi.ApiDomain, cmcproapi.ApiVersion)
+//
+// A request failing with HTTP status 401, 402 or 429 or with error code 1008
+// or 1010 is retried with another key, and the failed key is benched until
+// its limit resets. Rate limits reset every minute. Daily and monthly credit
+// limits reset at the times reported by key info, see SetKeyInfo; unless they
+// are known the pool assumes midnight UTC and the first day of the month,
+// while the monthly limit of a plan actually resets on its own billing date.
+// Invalid keys and keys requiring payment are benched for a day. If all keys
+// are benched requests fail without reaching the API.
+type KeyPool struct {
+	strategy KeyStrategy
+
+	mu   sync.Mutex
+	keys []*poolKey
+	next int
+}
+
+// poolKey is a key of the pool along with its usage.
+type poolKey struct {
+	key          string
+	requests     int
+	credits      int
+	failures     int
+	errorCode    int
+	benchedUntil time.Time
+	dailyReset   time.Time
+	monthlyReset time.Time
+}
+
+// KeyPoolUsage reports usage of a key since it was added to the pool.
+//
+// Key is masked except the last four characters. ErrorCode is the error code
+// of the last failure which benched the key.
+type KeyPoolUsage struct {
+	Key          string
+	Requests     int
+	Credits      int
+	Failures     int
+	ErrorCode    int
+	BenchedUntil time.Time
+}
+
+// Benched reports whether the key is not used until BenchedUntil.
+func (u KeyPoolUsage) Benched() bool {
+	return time.Now().Before(u.BenchedUntil)
+}
+
+// NewKeyPool returns a KeyPool of keys, which are in order of priority for KeyPriority.
+func NewKeyPool(strategy KeyStrategy, keys ...string) *KeyPool {
+	p := &KeyPool{strategy: strategy}
+	p.Add(keys...)
+	return p
+}
+
+// Add appends keys to the pool, empty and known keys are skipped.
+func (p *KeyPool) Add(keys ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, key := range keys {
+		if key == "" || p.find(key) != nil {
+			continue
+		}
+		p.keys = append(p.keys, &poolKey{key: key})
+	}
+}
+
+// Len returns the number of keys in the pool.
+func (p *KeyPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.keys)
+}
+
+// Usage returns usage of all keys in the pool.
+func (p *KeyPool) Usage() []KeyPoolUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	usage := make([]KeyPoolUsage, len(p.keys))
+	for i, k := range p.keys {
+		usage[i] = KeyPoolUsage{
+			Key:          maskKey(k.key),
+			Requests:     k.requests,
+			Credits:      k.credits,
+			Failures:     k.failures,
+			ErrorCode:    k.errorCode,
+			BenchedUntil: k.benchedUntil,
+		}
+	}
+	return usage
+}
+
+// SetKeyInfo records the reset times of the daily and monthly credit limits of
+// key, e.g. from GetKeyInfo of a Client using the key, so that a key benched by
+// its limit returns when the limit of its plan resets. Unknown keys are skipped.
+func (p *KeyPool) SetKeyInfo(key string, info KeyInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k := p.find(key); k != nil {
+		k.dailyReset = time.Time(info.Plan.CreditLimitDailyResetTimestamp)
+		k.monthlyReset = time.Time(info.Plan.CreditLimitMonthlyResetTimestamp)
+	}
+}
+
+func (p *KeyPool) find(key string) *poolKey {
+	for _, k := range p.keys {
+		if k.key == key {
+			return k
+		}
+	}
+	return nil
+}
+
+// pick returns the key for the next request according to the strategy,
+// skipping benched keys and those in tried.
+func (p *KeyPool) pick(tried map[string]bool) (key string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var best *poolKey
+	for i := range p.keys {
+		j := i
+		if p.strategy == KeyRoundRobin {
+			j = (p.next + i) % len(p.keys)
+		}
+		k := p.keys[j]
+		if tried[k.key] || now.Before(k.benchedUntil) {
+			continue
+		}
+		if p.strategy != KeyLeastUsed {
+			if p.strategy == KeyRoundRobin {
+				p.next = j + 1
+			}
+			return k.key, true
+		}
+		if best == nil || k.credits < best.credits ||
+			(k.credits == best.credits && k.requests < best.requests) {
+			best = k
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+	return best.key, true
+}
+
+// report records the outcome of a request with key and returns whether the
+// request should be retried with another key.
+func (p *KeyPool) report(key string, httpStatus int, status ResponseStatus) (failover bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := p.find(key)
+	if k == nil {
+		return false
+	}
+	k.requests++
+	k.credits += status.CreditCount
+	until := benchUntil(time.Now(), httpStatus, status.ErrorCode, k.dailyReset, k.monthlyReset)
+	if until.IsZero() {
+		return false
+	}
+	k.failures++
+	k.errorCode = status.ErrorCode
+	k.benchedUntil = until
+	return true
+}
+
+// benchUntil returns when a key failing with the status may be used again,
+// zero if the failure is not caused by the key. Known reset times of credit
+// limits are advanced by their period until they are ahead of now.
+func benchUntil(now time.Time, httpStatus, errorCode int, dailyReset, monthlyReset time.Time) time.Time {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch {
+	case errorCode == errorCodeMinuteRateLimit:
+		return now.Truncate(time.Minute).Add(time.Minute)
+	case errorCode == errorCodeDailyRateLimit && !dailyReset.IsZero():
+		return nextReset(now, dailyReset, 0, 1)
+	case errorCode == errorCodeDailyRateLimit:
+		return day.AddDate(0, 0, 1)
+	case errorCode == errorCodeMonthlyRateLimit && !monthlyReset.IsZero():
+		return nextReset(now, monthlyReset, 1, 0)
+	case errorCode == errorCodeMonthlyRateLimit:
+		return day.AddDate(0, 1, 1-now.Day())
+	case httpStatus == http.StatusTooManyRequests:
+		return now.Truncate(time.Minute).Add(time.Minute)
+	case httpStatus == http.StatusUnauthorized || httpStatus == http.StatusPaymentRequired:
+		return now.Add(24 * time.Hour)
+	}
+	return time.Time{}
+}
+
+// nextReset returns the first reset after now, advancing reset by periods of
+// months and days. Periods are counted from the original reset and the day is
+// clamped to the end of shorter months, so a reset on the 31st falls on the
+// last day of February and on the 31st again in March.
+func nextReset(now, reset time.Time, months, days int) time.Time {
+	reset = reset.UTC()
+	next := reset
+	for n := 1; !next.After(now); n++ {
+		next = addMonths(reset, n*months).AddDate(0, 0, n*days)
+	}
+	return next
+}
+
+// addMonths adds months to t, clamping the day to the last day of the month.
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1,
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	day := t.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// maskKey hides all characters of key except the last four.
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return key
+	}
+	masked := make([]byte, len(key))
+	for i := range masked {
+		masked[i] = '*'
+	}
+	copy(masked[len(key)-4:], key[len(key)-4:])
+	return string(masked)
+}
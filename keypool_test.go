@@ -0,0 +1,144 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+const testSpareKey = "0f5c2a61-7c4e-4d0a-9b8e-3f0d2c1b7a95"
+
+func TestKeyPoolFailover(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	srv.AddKey(testSpareKey)
+	pool := NewKeyPool(KeyPriority, cmcproapitest.APIKey, testSpareKey, "")
+	var attempts []int
+	hook := HookFuncs{Before: func(ctx context.Context, req *RequestInfo) {
+		attempts = append(attempts, req.Attempt)
+	}}
+	cmc, _ := NewCustom(pool, srv.URL, TestApiVersion, &http.Client{}, time.Second, hook)
+
+	srv.SetFault("", cmcproapitest.Fault{
+		ErrorCode: cmcproapitest.ErrorCodeMonthlyRateLimit, Key: cmcproapitest.APIKey})
+	if _, err := cmc.GetGlobalQuotesLatestBySymbol("USD"); err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 2 || attempts[1] != 2 {
+		t.Errorf("unexpected attempts %v", attempts)
+	}
+	// the benched key is skipped without a request
+	if _, err := cmc.GetGlobalQuotesLatestBySymbol("EUR"); err != nil {
+		t.Fatal(err)
+	}
+	usage := pool.Usage()
+	if len(usage) != 2 || !usage[0].Benched() || usage[0].Failures != 1 ||
+		usage[0].ErrorCode != cmcproapitest.ErrorCodeMonthlyRateLimit ||
+		usage[1].Requests != 2 || usage[1].Credits != 2 || usage[1].Benched() {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+	if usage[1].Key != "********************************7a95" {
+		t.Errorf("unexpected masked key %q", usage[1].Key)
+	}
+
+	srv.SetFault("", cmcproapitest.Fault{ErrorCode: cmcproapitest.ErrorCodeMinuteRateLimit})
+	if _, err := cmc.GetKeyInfo(); err == nil {
+		t.Error("expected rate limit error")
+	}
+	if _, err := cmc.GetKeyInfo(); err == nil || err.Error() != ltMsgKeysBenched {
+		t.Errorf("expected benched keys error, got %v", err)
+	}
+	if calls := srv.Calls(EndpointKeyInfo); calls != 1 {
+		t.Errorf("key/info requested %d times", calls)
+	}
+}
+
+func TestKeyPoolPick(t *testing.T) {
+	rr := NewKeyPool(KeyRoundRobin, "a", "b", "c", "b")
+	var keys string
+	for i := 0; i < 4; i++ {
+		key, _ := rr.pick(nil)
+		keys += key
+	}
+	if keys != "abca" {
+		t.Errorf("round robin picked %s", keys)
+	}
+	rr.report("b", http.StatusUnauthorized, ResponseStatus{ErrorCode: 1001})
+	if key, _ := rr.pick(map[string]bool{"c": true}); key != "a" {
+		t.Errorf("round robin picked %s after failure", key)
+	}
+
+	lu := NewKeyPool(KeyLeastUsed, "a", "b")
+	lu.report("a", http.StatusOK, ResponseStatus{CreditCount: 5})
+	lu.report("b", http.StatusOK, ResponseStatus{CreditCount: 2})
+	if key, _ := lu.pick(nil); key != "b" {
+		t.Errorf("least used picked %s", key)
+	}
+	if _, ok := lu.pick(map[string]bool{"a": true, "b": true}); ok {
+		t.Error("expected no key left")
+	}
+}
+
+func TestBenchUntil(t *testing.T) {
+	now := time.Date(2024, time.March, 15, 10, 30, 20, 0, time.UTC)
+	daily := time.Date(2024, time.March, 1, 6, 0, 0, 0, time.UTC)
+	monthly := time.Date(2024, time.January, 20, 6, 0, 0, 0, time.UTC)
+	tests := []struct {
+		httpStatus, errorCode int
+		dailyReset            time.Time
+		monthlyReset          time.Time
+		want                  time.Time
+	}{
+		{http.StatusOK, 0, time.Time{}, time.Time{}, time.Time{}},
+		{http.StatusBadRequest, 400, time.Time{}, time.Time{}, time.Time{}},
+		{http.StatusTooManyRequests, 1008, time.Time{}, time.Time{}, time.Date(2024, time.March, 15, 10, 31, 0, 0, time.UTC)},
+		{http.StatusTooManyRequests, 1009, time.Time{}, time.Time{}, time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{http.StatusTooManyRequests, 1010, time.Time{}, time.Time{}, time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{http.StatusPaymentRequired, 1003, time.Time{}, time.Time{}, now.Add(24 * time.Hour)},
+		{http.StatusTooManyRequests, 1009, daily, monthly, time.Date(2024, time.March, 15, 6, 0, 0, 0, time.UTC).AddDate(0, 0, 1)},
+		{http.StatusTooManyRequests, 1010, daily, monthly, time.Date(2024, time.March, 20, 6, 0, 0, 0, time.UTC)},
+		{http.StatusTooManyRequests, 1010, daily, now.AddDate(0, 0, 3), now.AddDate(0, 0, 3)},
+	}
+	for _, tt := range tests {
+		got := benchUntil(now, tt.httpStatus, tt.errorCode, tt.dailyReset, tt.monthlyReset)
+		if !got.Equal(tt.want) {
+			t.Errorf("benchUntil(%d, %d) = %v, want %v", tt.httpStatus, tt.errorCode, got, tt.want)
+		}
+	}
+}
+
+func TestNextReset(t *testing.T) {
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2024, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	reset := at(time.January, 31, 6)
+	for _, tt := range []struct{ now, want time.Time }{
+		{at(time.February, 1, 0), at(time.February, 29, 6)},
+		{at(time.February, 29, 6), at(time.March, 31, 6)},
+		{at(time.April, 30, 7), at(time.May, 31, 6)},
+	} {
+		if got := nextReset(tt.now, reset, 1, 0); !got.Equal(tt.want) {
+			t.Errorf("nextReset(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestSetKeyInfo(t *testing.T) {
+	reset := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	var info KeyInfo
+	info.Plan.CreditLimitMonthlyResetTimestamp = jsonTime(reset)
+	pool := NewKeyPool(KeyPriority, "a", "b")
+	pool.SetKeyInfo("a", info)
+	pool.SetKeyInfo("c", info)
+	pool.report("a", http.StatusTooManyRequests, ResponseStatus{ErrorCode: 1010})
+	if until := pool.Usage()[0].BenchedUntil; !until.Equal(reset) {
+		t.Errorf("benched until %v, want %v", until, reset)
+	}
+}
@@ -0,0 +1,80 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeySource supplies the API key of every request, so that the key may be
+// rotated without restarting the service. Pass it to NewCustom in place of
+// the key, e.g.
+//
+//	cmc, _ := cmcproapi.NewCustom(cmcproapi.NewFileKey("/run/secrets/cmc"),
+//		cmcproapi.ApiDomain, cmcproapi.ApiVersion)
+//
+// Implementations must be safe for concurrent use. A request is not sent if
+// Key returns an error.
+type KeySource interface {
+	Key(ctx context.Context) (string, error)
+}
+
+// EnvKey reads the key from the environment variable it names, e.g.
+// EnvKey("CMC_PRO_API_KEY").
+type EnvKey string
+
+// Key returns the value of the environment variable.
+func (e EnvKey) Key(ctx context.Context) (string, error) {
+	return os.Getenv(string(e)), nil
+}
+
+// KeyFunc adapts a callback to KeySource, e.g. to fetch the key from a vault.
+type KeyFunc func(ctx context.Context) (string, error)
+
+// Key calls f.
+func (f KeyFunc) Key(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// FileKey reads the key from a file, e.g. a Docker or Kubernetes secret mount.
+// The file is read again whenever its size or modification time changes and
+// surrounding whitespace is trimmed.
+type FileKey struct {
+	path string
+
+	mu      sync.Mutex
+	key     string
+	size    int64
+	modTime time.Time
+}
+
+// NewFileKey returns a FileKey reading path.
+func NewFileKey(path string) *FileKey {
+	return &FileKey{path: path}
+}
+
+// Key returns the content of the file.
+func (f *FileKey) Key(ctx context.Context) (key string, err error) {
+	var fi os.FileInfo
+	if fi, err = os.Stat(f.path); err != nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if fi.Size() == f.size && fi.ModTime().Equal(f.modTime) && f.key != "" {
+		return f.key, nil
+	}
+	var data []byte
+	if data, err = ioutil.ReadFile(f.path); err != nil {
+		return
+	}
+	f.key, f.size, f.modTime = strings.TrimSpace(string(data)), fi.Size(), fi.ModTime()
+	return f.key, nil
+}
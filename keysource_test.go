@@ -0,0 +1,100 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+func TestFileKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cmc-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "key")
+	if err = ioutil.WriteFile(path, []byte("revoked\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmc, _ := NewCustom(NewFileKey(path), testServer.URL, TestApiVersion,
+		&http.Client{}, time.Second)
+	if _, err = cmc.GetKeyInfo(); err == nil {
+		t.Fatal("expected invalid key error")
+	}
+	// rotate the key, the modification time is set explicitly as the file
+	// system may not resolve writes within the same tick
+	if err = ioutil.WriteFile(path, []byte(cmcproapitest.APIKey+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err = os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cmc.GetKeyInfo(); err != nil {
+		t.Error(err)
+	}
+
+	os.Remove(path)
+	if _, err = cmc.GetKeyInfo(); err == nil {
+		t.Error("expected error of missing file")
+	}
+}
+
+func TestEnvKey(t *testing.T) {
+	defer os.Setenv(ltCmcProApiKey, os.Getenv(ltCmcProApiKey))
+	os.Setenv(ltCmcProApiKey, cmcproapitest.APIKey)
+	cmc, _ := New("")
+	cmc.apiDomain, cmc.apiVersion = testServer.URL, TestApiVersion
+	if _, err := cmc.GetKeyInfo(); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv(ltCmcProApiKey, "")
+	if _, err := cmc.GetKeyInfo(); err == nil {
+		t.Error("expected missing key error")
+	}
+}
+
+func TestKeyFunc(t *testing.T) {
+	calls := testServer.Calls(EndpointKeyInfo)
+	fail := errors.New("vault sealed")
+	cmc, _ := NewCustom(KeyFunc(func(ctx context.Context) (string, error) {
+		return "", fail
+	}), testServer.URL, TestApiVersion, &http.Client{}, time.Second)
+	if _, err := cmc.GetKeyInfo(); err != fail {
+		t.Errorf("expected callback error, got %v", err)
+	}
+	if testServer.Calls(EndpointKeyInfo) != calls {
+		t.Error("request sent without a key")
+	}
+}
+
+func TestKeyFuncCanceled(t *testing.T) {
+	aborted := make(chan error, 1)
+	cmc, _ := NewCustom(KeyFunc(func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		aborted <- ctx.Err()
+		return "", ctx.Err()
+	}), testServer.URL, TestApiVersion, &http.Client{}, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var data interface{}
+	if _, err := cmc.Get(ctx, "", EndpointKeyInfo, nil, &data); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Error("key source not canceled")
+	}
+}
@@ -9,8 +9,27 @@ const (
 	ltCmcProApiKeyX = "X-CMC_PRO_API_KEY"
 
 	ltMsgEmptyArgs       = "empty arguments"
+	ltMsgEmptyEndpoint   = "empty endpoint"
 	ltMsgRequestExceeded = "request exceeded the timelimit"
 	ltMsgUnsupArgType    = "unsupported argument type"
+	ltMsgKeysBenched     = "all API keys are benched"
+
+	ltMsgQueryAddress    = "address is mutually exclusive with id, symbol and slug"
+	ltMsgQueryAmount     = "amount is out of range"
+	ltMsgQueryConvert    = "convert and convert_id are mutually exclusive"
+	ltMsgQueryConvertMax = "too many convert options"
+	ltMsgQueryCount      = "count is out of range"
+	ltMsgQueryExclusive  = "id, symbol and slug are mutually exclusive"
+	ltMsgQueryId         = "id must be positive"
+	ltMsgQueryIdentity   = "one of id, symbol or slug is required"
+	ltMsgQueryLimit      = "limit is out of range"
+	ltMsgQuerySortDir    = "sort_dir must be asc or desc"
+	ltMsgQueryStart      = "start must be positive"
+	ltMsgQueryTimePeriod = "time_period must be daily or hourly"
+	ltMsgQueryTimeRange  = "time_end is before time_start"
+
+	ltMsgConvertNoRate   = "no conversion rate"
+	ltMsgResolveNotFound = "cryptocurrency not found"
 
 	ltUriCurrencyMap            = "cryptocurrency/map"
 	ltUriCurrencyInfo           = "cryptocurrency/info"
@@ -18,4 +37,5 @@ const (
 	ltUriCurrencyQuotesLatest   = "cryptocurrency/quotes/latest"
 	ltUriGlobalQuotesLatest     = "global-metrics/quotes/latest"
 	ltUriToolsPriceConversion   = "tools/price-conversion"
+	ltUriKeyInfo                = "key/info"
 )
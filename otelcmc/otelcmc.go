@@ -0,0 +1,115 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+// Package otelcmc traces requests of cmcproapi.Client with OpenTelemetry.
+//
+// It is a separate module, so that the client itself stays free of
+// dependencies. Every upstream request becomes a client span named after the
+// endpoint and parented by the span of the context passed to the client:
+//
+//	cmc, _ := cmcproapi.NewCustom(apiKey, cmcproapi.ApiDomain, cmcproapi.ApiVersion,
+//		&http.Client{}, time.Minute, otelcmc.NewHook())
+//	cmc.GetCurrencyQuotesLatestByIds(ctx, ids, convert, cmcproapi.BatchOptions{})
+package otelcmc
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of created spans.
+const ScopeName = "github.com/nikchis/cmc-proapi/otelcmc"
+
+// Attribute keys of created spans besides the HTTP semantic conventions.
+const (
+	EndpointKey    = attribute.Key("cmc.endpoint")
+	QueryKeyPrefix = "cmc.query."
+	ErrorCodeKey   = attribute.Key("cmc.error_code")
+	CreditCountKey = attribute.Key("cmc.credit_count")
+	AttemptKey     = attribute.Key("cmc.attempt")
+)
+
+// Option configures the hook.
+type Option func(*config)
+
+type config struct {
+	provider trace.TracerProvider
+}
+
+// WithTracerProvider sets the provider of the tracer, the global one is used by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// hook starts a span before each request and ends it after the response.
+type hook struct {
+	tracer trace.Tracer
+
+	mu    sync.Mutex
+	spans map[*cmcproapi.RequestInfo]trace.Span
+}
+
+// NewHook returns a cmcproapi.Hook tracing requests, pass it to cmcproapi.NewCustom.
+func NewHook(opts ...Option) cmcproapi.Hook {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.provider == nil {
+		cfg.provider = otel.GetTracerProvider()
+	}
+	return &hook{
+		tracer: cfg.provider.Tracer(ScopeName),
+		spans:  map[*cmcproapi.RequestInfo]trace.Span{},
+	}
+}
+
+// BeforeRequest starts the span of the request.
+func (h *hook) BeforeRequest(ctx context.Context, req *cmcproapi.RequestInfo) {
+	attrs := []attribute.KeyValue{
+		EndpointKey.String(req.Endpoint),
+		attribute.String("http.request.method", req.Method),
+		AttemptKey.Int(req.Attempt),
+	}
+	for k, v := range req.Query {
+		attrs = append(attrs, attribute.String(QueryKeyPrefix+k, strings.Join(v, ",")))
+	}
+	_, span := h.tracer.Start(ctx, req.Endpoint,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	h.mu.Lock()
+	h.spans[req] = span
+	h.mu.Unlock()
+}
+
+// AfterResponse records the outcome and ends the span of the request.
+func (h *hook) AfterResponse(ctx context.Context, req *cmcproapi.RequestInfo, resp *cmcproapi.ResponseInfo) {
+	h.mu.Lock()
+	span, ok := h.spans[req]
+	delete(h.spans, req)
+	h.mu.Unlock()
+	if !ok {
+		return
+	}
+	if resp.HTTPStatus != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.HTTPStatus))
+	}
+	span.SetAttributes(
+		ErrorCodeKey.Int(resp.Status.ErrorCode),
+		CreditCountKey.Int(resp.Status.CreditCount),
+	)
+	if resp.Err != nil {
+		span.RecordError(resp.Err)
+		span.SetStatus(codes.Error, resp.Err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,72 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package otelcmc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHook(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	cmc, _ := cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, cmcproapi.ApiVersion,
+		&http.Client{}, time.Second, NewHook(WithTracerProvider(provider)))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	if _, err := cmc.GetCurrencyQuotesLatestByIds(ctx, []int{1, 1027}, []string{"USD"},
+		cmcproapi.BatchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+	srv.SetFault(cmcproapi.EndpointKeyInfo, cmcproapitest.Fault{
+		ErrorCode: cmcproapitest.ErrorCodeDailyRateLimit, Count: 1})
+	if _, err := cmc.GetKeyInfo(); err == nil {
+		t.Fatal("expected rate limit error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("recorded %d spans", len(spans))
+	}
+	quotes, key := spans[0], spans[2]
+	if quotes.Name() != cmcproapi.EndpointCurrencyQuotesLatest || quotes.SpanKind() != trace.SpanKindClient ||
+		quotes.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("unexpected span %s of kind %v", quotes.Name(), quotes.SpanKind())
+	}
+	want := map[attribute.Key]attribute.Value{
+		EndpointKey:                 attribute.StringValue(cmcproapi.EndpointCurrencyQuotesLatest),
+		QueryKeyPrefix + "id":       attribute.StringValue("1,1027"),
+		QueryKeyPrefix + "convert":  attribute.StringValue("USD"),
+		"http.response.status_code": attribute.IntValue(200),
+		ErrorCodeKey:                attribute.IntValue(0),
+		CreditCountKey:              attribute.IntValue(1),
+		AttemptKey:                  attribute.IntValue(1),
+	}
+	got := map[attribute.Key]attribute.Value{}
+	for _, kv := range quotes.Attributes() {
+		got[kv.Key] = kv.Value
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("attribute %s = %v, want %v", k, got[k].Emit(), v.Emit())
+		}
+	}
+	if key.Status().Code != codes.Error || len(key.Events()) == 0 {
+		t.Errorf("failed request span has status %v", key.Status())
+	}
+}
@@ -0,0 +1,165 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// PageLimit bounds automatic pagination, zero values mean no bound.
+//
+// MaxCredits is checked before each page is requested, so the last page
+// may exceed it by the credit cost of a single request.
+type PageLimit struct {
+	MaxPages   int
+	MaxCredits int
+}
+
+// pager keeps start/limit bookkeeping shared by all list iterators.
+type pager struct {
+	ctx     context.Context
+	client  *Client
+	limit   PageLimit
+	start   int
+	size    int
+	pages   int
+	credits int
+	done    bool
+	err     error
+}
+
+func newPager(ctx context.Context, c *Client, start, size int, limit PageLimit) pager {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if start == 0 {
+		start = 1
+	}
+	if size == 0 {
+		size = QueryLimitMax
+	}
+	return pager{ctx: ctx, client: c, limit: limit, start: start, size: size}
+}
+
+// fetch requests the next page of endpoint into result and reports whether
+// any rows were received.
+func (p *pager) fetch(endpoint string, q url.Values, result interface{}, rows func() int) bool {
+	if p.done || p.err != nil {
+		return false
+	}
+	if (p.limit.MaxPages > 0 && p.pages >= p.limit.MaxPages) ||
+		(p.limit.MaxCredits > 0 && p.credits >= p.limit.MaxCredits) {
+		p.done = true
+		return false
+	}
+	var raw json.RawMessage
+	var status ResponseStatus
+	q.Set("start", strconv.Itoa(p.start))
+	q.Set("limit", strconv.Itoa(p.size))
+	raw, p.err = p.client.handleRequest(p.ctx, endpoint, &q, &status)
+	p.credits += status.CreditCount
+	if p.err != nil {
+		return false
+	}
+	if p.err = p.client.unmarshal(raw, result); p.err != nil {
+		return false
+	}
+	p.pages++
+	n := rows()
+	p.start += n
+	if n < p.size {
+		p.done = true
+	}
+	return n > 0
+}
+
+// Pages returns the number of pages fetched so far.
+func (p *pager) Pages() int {
+	return p.pages
+}
+
+// Credits returns the number of API credits spent so far.
+func (p *pager) Credits() int {
+	return p.credits
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (p *pager) Err() error {
+	return p.err
+}
+
+// CurrencyMapIterator pages through cryptocurrency/map results.
+type CurrencyMapIterator struct {
+	pager
+	query url.Values
+	page  []CurrencyMap
+	pos   int
+}
+
+// IterCurrencyMap returns an iterator over all rows matching the query. Start and
+// Limit of the query set the first row and the page size respectively.
+//
+//	it := cmc.IterCurrencyMap(ctx, query, PageLimit{MaxPages: 10})
+//	for it.Next() {
+//		item := it.Value()
+//	}
+//	err := it.Err()
+func (c *Client) IterCurrencyMap(
+	ctx context.Context, query CurrencyMapQuery, limit PageLimit) *CurrencyMapIterator {
+	it := &CurrencyMapIterator{pager: newPager(ctx, c, query.Start, query.Limit, limit)}
+	it.query, it.err = query.Values()
+	return it
+}
+
+// Next advances the iterator to the next row, fetching a new page when required.
+func (it *CurrencyMapIterator) Next() bool {
+	if it.pos+1 < len(it.page) {
+		it.pos++
+		return true
+	}
+	it.page, it.pos = nil, 0
+	return it.fetch(ltUriCurrencyMap, it.query, &it.page, func() int { return len(it.page) })
+}
+
+// Value returns the current row.
+func (it *CurrencyMapIterator) Value() CurrencyMap {
+	return it.page[it.pos]
+}
+
+// CurrencyListingsIterator pages through cryptocurrency/listings/latest results.
+type CurrencyListingsIterator struct {
+	pager
+	query url.Values
+	page  []CurrencyListing
+	pos   int
+}
+
+// IterCurrencyListingsLatest returns an iterator over all listings matching the query.
+// Start and Limit of the query set the first row and the page size respectively.
+func (c *Client) IterCurrencyListingsLatest(
+	ctx context.Context, query CurrencyListingsQuery, limit PageLimit) *CurrencyListingsIterator {
+	it := &CurrencyListingsIterator{pager: newPager(ctx, c, query.Start, query.Limit, limit)}
+	it.query, it.err = query.Values()
+	return it
+}
+
+// Next advances the iterator to the next row, fetching a new page when required.
+func (it *CurrencyListingsIterator) Next() bool {
+	if it.pos+1 < len(it.page) {
+		it.pos++
+		return true
+	}
+	it.page, it.pos = nil, 0
+	return it.fetch(ltUriCurrencyListingsLatest, it.query, &it.page,
+		func() int { return len(it.page) })
+}
+
+// Value returns the current row.
+func (it *CurrencyListingsIterator) Value() CurrencyListing {
+	return it.page[it.pos]
+}
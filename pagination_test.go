@@ -0,0 +1,68 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newPagedServer returns a server listing total currencies in cryptocurrency/map.
+func newPagedServer(total int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var rows []string
+		for id := start; id < start+limit && id <= total; id++ {
+			rows = append(rows, fmt.Sprintf(`{"id":%d,"symbol":"C%d"}`, id, id))
+		}
+		fmt.Fprintf(w, `{"data":[%s],"status":{"error_code":0,"credit_count":1}}`,
+			strings.Join(rows, ","))
+	}))
+}
+
+func TestIterCurrencyMap(t *testing.T) {
+	srv := newPagedServer(25)
+	defer srv.Close()
+	cmc, _ := NewCustom(TestApiKey, srv.URL, ApiVersion, &http.Client{}, time.Second)
+	it := cmc.IterCurrencyMap(context.Background(), CurrencyMapQuery{Limit: 10}, PageLimit{})
+	n := 0
+	for it.Next() {
+		n++
+		if it.Value().Id != n {
+			t.Errorf("unexpected id %d at row %d", it.Value().Id, n)
+		}
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if n != 25 || it.Pages() != 3 || it.Credits() != 3 {
+		t.Errorf("got %d rows, %d pages, %d credits", n, it.Pages(), it.Credits())
+	}
+}
+
+func TestIterCurrencyMapPageLimit(t *testing.T) {
+	srv := newPagedServer(100)
+	defer srv.Close()
+	cmc, _ := NewCustom(TestApiKey, srv.URL, ApiVersion, &http.Client{}, time.Second)
+	it := cmc.IterCurrencyMap(context.Background(), CurrencyMapQuery{Limit: 10},
+		PageLimit{MaxCredits: 2})
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if n != 20 {
+		t.Errorf("expected 20 rows, got %d", n)
+	}
+}
@@ -0,0 +1,326 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+// Package poller periodically fetches latest quotes for a watched set of
+// cryptocurrencies and delivers changes since the previous poll.
+//
+//	p := poller.New(cmc, poller.Options{Convert: []string{"USD"}})
+//	p.Watch(1, 1027)
+//	ticks, cancel := p.Subscribe(16)
+//	defer cancel()
+//	p.Start(ctx)
+//	defer p.Stop()
+//	for tick := range ticks {
+//		for _, u := range tick.Updates {
+//			fmt.Println(u.Current.Symbol, (*u.Current.Quote)["USD"].Price)
+//		}
+//	}
+package poller
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+)
+
+// DefaultInterval matches the refresh rate of CoinMarketCap latest quotes.
+const DefaultInterval = time.Minute
+
+// Options of Poller.
+//
+// Polls happen at multiples of Interval plus Offset, so that several pollers
+// and the CoinMarketCap refresh cycle stay aligned. Convert defaults to USD.
+// With Global set, global metrics are polled along with quotes.
+type Options struct {
+	Interval time.Duration
+	Offset   time.Duration
+	Convert  []string
+	Global   bool
+	Batch    cmcproapi.BatchOptions
+}
+
+// Update is a change of a watched cryptocurrency keyed by id or symbol as watched.
+// Previous is nil on the first poll.
+type Update struct {
+	Key      string
+	Current  cmcproapi.CurrencyListing
+	Previous *cmcproapi.CurrencyListing
+}
+
+// Tick is the result of a poll. It is delivered only when something has changed
+// or the poll has failed. A failed poll may still carry partial updates.
+// Sandbox marks mock data of the sandbox environment.
+type Tick struct {
+	Time           time.Time
+	Updates        []Update
+	Global         *cmcproapi.GlobalMetrics
+	PreviousGlobal *cmcproapi.GlobalMetrics
+	Sandbox        bool
+	Err            error
+}
+
+// Poller polls quotes of watched cryptocurrencies.
+type Poller struct {
+	client *cmcproapi.Client
+	opt    Options
+
+	mu        sync.Mutex
+	ids       map[int]bool
+	symbols   map[string]bool
+	last      map[string]cmcproapi.CurrencyListing
+	global    *cmcproapi.GlobalMetrics
+	subs      map[chan Tick]bool
+	callbacks []func(Tick)
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// New returns an instantiated Poller, it does not poll until started.
+func New(c *cmcproapi.Client, opt Options) *Poller {
+	if opt.Interval <= 0 {
+		opt.Interval = DefaultInterval
+	}
+	if len(opt.Convert) == 0 {
+		opt.Convert = []string{"USD"}
+	}
+	return &Poller{
+		client:  c,
+		opt:     opt,
+		ids:     make(map[int]bool),
+		symbols: make(map[string]bool),
+		last:    make(map[string]cmcproapi.CurrencyListing),
+		subs:    make(map[chan Tick]bool),
+	}
+}
+
+// Watch adds cryptocurrencies by id, they are polled starting with the next tick.
+func (p *Poller) Watch(ids ...int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, id := range ids {
+		p.ids[id] = true
+	}
+}
+
+// WatchSymbols adds cryptocurrencies by symbol, symbols are case-insensitive.
+func (p *Poller) WatchSymbols(symbols ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, s := range symbols {
+		if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+			p.symbols[s] = true
+		}
+	}
+}
+
+// Unwatch removes cryptocurrencies by id.
+func (p *Poller) Unwatch(ids ...int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, id := range ids {
+		delete(p.ids, id)
+		delete(p.last, strconv.Itoa(id))
+	}
+}
+
+// UnwatchSymbols removes cryptocurrencies by symbol.
+func (p *Poller) UnwatchSymbols(symbols ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, s := range symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		delete(p.symbols, s)
+		delete(p.last, s)
+	}
+}
+
+// Subscribe returns a channel receiving ticks and a function to unsubscribe.
+// Ticks are dropped when the channel buffer is full, so a slow subscriber
+// never delays polling. The channel is closed by Stop or unsubscribe.
+func (p *Poller) Subscribe(buffer int) (<-chan Tick, func()) {
+	ch := make(chan Tick, buffer)
+	p.mu.Lock()
+	p.subs[ch] = true
+	p.mu.Unlock()
+	return ch, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.subs[ch] {
+			delete(p.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// OnTick registers a callback run synchronously after each delivered tick.
+func (p *Poller) OnTick(fn func(Tick)) {
+	p.mu.Lock()
+	p.callbacks = append(p.callbacks, fn)
+	p.mu.Unlock()
+}
+
+// Start polls immediately and then on every aligned interval until ctx
+// is done or Stop is called.
+func (p *Poller) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done != nil {
+		return
+	}
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+	go p.loop(ctx, p.done)
+}
+
+// Stop stops polling, waits for the running poll to finish and closes
+// subscribed channels.
+func (p *Poller) Stop() {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.mu.Unlock()
+	if done == nil {
+		return
+	}
+	cancel()
+	<-done
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for ch := range p.subs {
+		close(ch)
+		delete(p.subs, ch)
+	}
+	p.cancel, p.done = nil, nil
+}
+
+func (p *Poller) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	for {
+		tick := p.Poll(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		p.deliver(tick)
+		now := time.Now()
+		next := now.Truncate(p.opt.Interval).Add(p.opt.Offset)
+		for !next.After(now) {
+			next = next.Add(p.opt.Interval)
+		}
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Poll fetches watched quotes once and returns the difference from the previous poll.
+func (p *Poller) Poll(ctx context.Context) (tick Tick) {
+	tick.Time = time.Now()
+	tick.Sandbox = p.client.Sandbox()
+	p.mu.Lock()
+	ids := make([]int, 0, len(p.ids))
+	for id := range p.ids {
+		ids = append(ids, id)
+	}
+	symbols := make([]string, 0, len(p.symbols))
+	for s := range p.symbols {
+		symbols = append(symbols, s)
+	}
+	p.mu.Unlock()
+	sort.Ints(ids)
+	sort.Strings(symbols)
+
+	current := cmcproapi.CurrencyListingMap{}
+	if len(ids) != 0 {
+		result, err := p.client.GetCurrencyQuotesLatestByIds(ctx, ids, p.opt.Convert, p.opt.Batch)
+		for k, v := range result {
+			current[k] = v
+		}
+		tick.Err = err
+	}
+	if len(symbols) != 0 && ctx.Err() == nil {
+		result, err := p.client.GetCurrencyQuotesLatestBySymbols(
+			ctx, symbols, p.opt.Convert, p.opt.Batch)
+		for k, v := range result {
+			current[k] = v
+		}
+		if err != nil {
+			tick.Err = err
+		}
+	}
+	var global *cmcproapi.GlobalMetrics
+	if p.opt.Global && ctx.Err() == nil {
+		result, err := p.client.GetGlobalQuotesLatestByQuery(ctx,
+			cmcproapi.GlobalQuotesQuery{Convert: p.opt.Convert})
+		if err != nil {
+			tick.Err = err
+		} else {
+			global = &result
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	keys := make([]string, 0, len(current))
+	for k := range current {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !p.watched(k) {
+			continue
+		}
+		cur := current[k]
+		prev, ok := p.last[k]
+		if ok && reflect.DeepEqual(prev, cur) {
+			continue
+		}
+		u := Update{Key: k, Current: cur}
+		if ok {
+			u.Previous = &prev
+		}
+		tick.Updates = append(tick.Updates, u)
+		p.last[k] = cur
+	}
+	if global != nil && (p.global == nil || !reflect.DeepEqual(*p.global, *global)) {
+		tick.Global, tick.PreviousGlobal = global, p.global
+		p.global = global
+	}
+	return
+}
+
+// watched reports whether key is still watched, it may have been removed during the poll.
+func (p *Poller) watched(key string) bool {
+	if id, err := strconv.Atoi(key); err == nil && p.ids[id] {
+		return true
+	}
+	return p.symbols[strings.ToUpper(key)]
+}
+
+// deliver sends the tick to subscribers and callbacks if there is anything to report.
+func (p *Poller) deliver(tick Tick) {
+	if len(tick.Updates) == 0 && tick.Global == nil && tick.Err == nil {
+		return
+	}
+	p.mu.Lock()
+	callbacks := p.callbacks
+	for ch := range p.subs {
+		select {
+		case ch <- tick:
+		default:
+		}
+	}
+	p.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(tick)
+	}
+}
@@ -0,0 +1,119 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package poller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+func newTestPoller(t *testing.T, opt Options) (*Poller, *cmcproapitest.Server) {
+	srv := cmcproapitest.NewServer()
+	cmc, err := cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, cmcproapi.ApiVersion,
+		&http.Client{}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(cmc, opt), srv
+}
+
+func TestPoll(t *testing.T) {
+	p, srv := newTestPoller(t, Options{Global: true})
+	defer srv.Close()
+	p.Watch(1, 1027)
+	p.WatchSymbols("LTC")
+	ctx := context.Background()
+	tick := p.Poll(ctx)
+	if tick.Err != nil {
+		t.Fatal(tick.Err)
+	}
+	if len(tick.Updates) != 3 || tick.Updates[0].Previous != nil || tick.Global == nil {
+		t.Fatalf("unexpected first tick: %+v", tick)
+	}
+	if tick = p.Poll(ctx); len(tick.Updates) != 0 || tick.Global != nil {
+		t.Errorf("expected no changes, got %+v", tick)
+	}
+	srv.SetPrice(1, 10000)
+	p.Unwatch(1027)
+	tick = p.Poll(ctx)
+	if len(tick.Updates) != 1 || tick.Updates[0].Key != "1" || tick.Updates[0].Previous == nil {
+		t.Fatalf("expected BTC update, got %+v", tick)
+	}
+	if price := (*tick.Updates[0].Current.Quote)["USD"].Price; price != 10000 {
+		t.Errorf("unexpected price %v", price)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	p, srv := newTestPoller(t, Options{Interval: 10 * time.Millisecond})
+	defer srv.Close()
+	p.Watch(1)
+	ticks, _ := p.Subscribe(4)
+	var called int
+	p.OnTick(func(Tick) { called++ })
+	p.Start(context.Background())
+	first := <-ticks
+	if len(first.Updates) != 1 {
+		t.Fatalf("unexpected tick: %+v", first)
+	}
+	srv.SetPrice(1, 12000)
+	select {
+	case tick := <-ticks:
+		if len(tick.Updates) != 1 || tick.Updates[0].Previous == nil {
+			t.Errorf("unexpected tick: %+v", tick)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no tick after price change")
+	}
+	p.Stop()
+	if _, ok := <-ticks; ok {
+		t.Error("expected channel to be closed")
+	}
+	if called != 2 {
+		t.Errorf("expected 2 callbacks, got %d", called)
+	}
+}
+
+func TestStopCancelsPoll(t *testing.T) {
+	for _, opt := range []Options{{Global: true}, {}} {
+		p, srv := newTestPoller(t, opt)
+		if !opt.Global {
+			p.WatchSymbols("BTC")
+		}
+		srv.SetLatency(5 * time.Second)
+		p.Start(context.Background())
+		time.Sleep(50 * time.Millisecond)
+		started := time.Now()
+		p.Stop()
+		if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+			t.Errorf("%+v: Stop took %v", opt, elapsed)
+		}
+		srv.CloseClientConnections()
+		srv.Close()
+	}
+}
+
+func TestWatchSymbolsCase(t *testing.T) {
+	p, srv := newTestPoller(t, Options{})
+	defer srv.Close()
+	p.WatchSymbols("btc", " Eth ")
+	tick := p.Poll(context.Background())
+	if tick.Err != nil {
+		t.Fatal(tick.Err)
+	}
+	if len(tick.Updates) != 2 || tick.Updates[0].Key != "BTC" || tick.Updates[1].Key != "ETH" {
+		t.Fatalf("unexpected tick: %+v", tick)
+	}
+	p.UnwatchSymbols("eth")
+	srv.SetPrice(1027, 5000)
+	if tick = p.Poll(context.Background()); len(tick.Updates) != 0 {
+		t.Errorf("expected no updates of unwatched symbol, got %+v", tick)
+	}
+}
@@ -0,0 +1,239 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+// Package portfolio values holdings of cryptocurrencies using latest quotes.
+//
+//	p := portfolio.Portfolio{
+//		{Symbol: "BTC", Quantity: 0.5, Cost: 20000},
+//		{Id: 1027, Quantity: 10, Cost: 15000},
+//	}
+//	report, err := p.Value(ctx, cmc, portfolio.Options{Convert: []string{"USD", "EUR"}})
+//	fmt.Println(report.Totals["USD"].Value, report.Totals["USD"].PnL)
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+)
+
+// Position is a holding of a cryptocurrency given by Id or Symbol.
+//
+// Cost is the total cost basis of the position in CostCurrency, which defaults
+// to the first convert currency. Profit and loss is reported in CostCurrency only.
+type Position struct {
+	Id           int
+	Symbol       string
+	Quantity     float64
+	Cost         float64
+	CostCurrency string
+}
+
+// Portfolio is a list of positions. The same cryptocurrency may be held in
+// several positions, e.g. lots bought at different prices.
+type Portfolio []Position
+
+// Options of valuation. Convert defaults to USD.
+type Options struct {
+	Convert []string
+	Batch   cmcproapi.BatchOptions
+}
+
+// Valuation of a position or of the portfolio in one convert currency.
+//
+// Weight is the share of the portfolio value in percents. PnL and PnLPercent
+// are zero unless the cost basis is known in the currency. Change24h and
+// Change7d are value changes derived from percent changes of the price.
+type Valuation struct {
+	Price      float64
+	Value      float64
+	Weight     float64
+	Cost       float64
+	PnL        float64
+	PnLPercent float64
+	Change24h  float64
+	Change7d   float64
+}
+
+// Holding is a valued position. Values are keyed by convert currency and are
+// empty if no quote was received for the position.
+type Holding struct {
+	Position Position
+	Listing  cmcproapi.CurrencyListing
+	Values   map[string]Valuation
+}
+
+// Report is the valuation of a portfolio, Totals are keyed by convert currency.
+type Report struct {
+	Time     time.Time
+	Holdings []Holding
+	Totals   map[string]Valuation
+}
+
+// Value fetches latest quotes of all positions in batches and values them.
+//
+// If some quotes could not be fetched the report still values the remaining
+// positions and the error describes the failures, it is of BatchErrors type
+// unless the request could not be started.
+func (p Portfolio) Value(ctx context.Context, c *cmcproapi.Client, opt Options) (report Report, err error) {
+	convert := converts(opt.Convert)
+	var ids []int
+	var symbols []string
+	for _, pos := range p {
+		switch {
+		case pos.Id > 0:
+			ids = append(ids, pos.Id)
+		case pos.Symbol != "":
+			symbols = append(symbols, strings.ToUpper(pos.Symbol))
+		default:
+			return report, errors.New("portfolio: position requires id or symbol")
+		}
+	}
+	report.Time = time.Now()
+	listings := cmcproapi.CurrencyListingMap{}
+	if len(ids) != 0 {
+		result, e := c.GetCurrencyQuotesLatestByIds(ctx, ids, convert, opt.Batch)
+		for k, v := range result {
+			listings[k] = v
+		}
+		err = e
+	}
+	if len(symbols) != 0 {
+		sort.Strings(symbols)
+		result, e := c.GetCurrencyQuotesLatestBySymbols(ctx, unique(symbols), convert, opt.Batch)
+		for k, v := range result {
+			listings[k] = v
+		}
+		err = joinErrors(err, e)
+	}
+	report.Holdings, report.Totals = value(p, listings, convert)
+	return
+}
+
+// Revalue values the portfolio from already fetched listings keyed by id or
+// symbol, e.g. delivered by the poller.
+func (p Portfolio) Revalue(listings cmcproapi.CurrencyListingMap, convert ...string) (report Report) {
+	convert = converts(convert)
+	byKey := cmcproapi.CurrencyListingMap{}
+	for k, v := range listings {
+		byKey[strings.ToUpper(k)] = v
+		byKey[strconv.Itoa(v.Id)] = v
+	}
+	for _, v := range listings {
+		if _, ok := byKey[strings.ToUpper(v.Symbol)]; !ok {
+			byKey[strings.ToUpper(v.Symbol)] = v
+		}
+	}
+	report.Time = time.Now()
+	report.Holdings, report.Totals = value(p, byKey, convert)
+	return
+}
+
+// value computes holdings and totals.
+func value(p Portfolio, listings cmcproapi.CurrencyListingMap,
+	convert []string) (holdings []Holding, totals map[string]Valuation) {
+	totals = make(map[string]Valuation, len(convert))
+	for _, pos := range p {
+		key := strings.ToUpper(pos.Symbol)
+		if pos.Id > 0 {
+			key = strconv.Itoa(pos.Id)
+		}
+		costCurrency := strings.ToUpper(pos.CostCurrency)
+		if costCurrency == "" {
+			costCurrency = strings.ToUpper(convert[0])
+		}
+		h := Holding{Position: pos, Values: map[string]Valuation{}}
+		l, ok := listings[key]
+		if ok && l.Quote != nil {
+			h.Listing = l
+			for _, cv := range convert {
+				q, ok := (*l.Quote)[cv]
+				if !ok {
+					continue
+				}
+				v := Valuation{Price: q.Price, Value: q.Price * pos.Quantity}
+				v.Change24h = change(v.Value, q.PercentChange24h)
+				v.Change7d = change(v.Value, q.PercentChange7d)
+				if strings.EqualFold(cv, costCurrency) && pos.Cost != 0 {
+					v.Cost = pos.Cost
+					v.PnL = v.Value - pos.Cost
+					v.PnLPercent = v.PnL / pos.Cost * 100
+				}
+				h.Values[cv] = v
+				t := totals[cv]
+				t.Value += v.Value
+				t.Cost += v.Cost
+				t.PnL += v.PnL
+				t.Change24h += v.Change24h
+				t.Change7d += v.Change7d
+				totals[cv] = t
+			}
+		}
+		holdings = append(holdings, h)
+	}
+	for cv, t := range totals {
+		if t.Cost != 0 {
+			t.PnLPercent = t.PnL / t.Cost * 100
+		}
+		t.Weight = 100
+		totals[cv] = t
+		for _, h := range holdings {
+			if v, ok := h.Values[cv]; ok && t.Value != 0 {
+				v.Weight = v.Value / t.Value * 100
+				h.Values[cv] = v
+			}
+		}
+	}
+	return
+}
+
+// change returns the absolute change which led to value by percent.
+func change(value, percent float64) float64 {
+	if percent <= -100 {
+		return 0
+	}
+	return value - value/(1+percent/100)
+}
+
+// converts returns upper-case convert currencies, USD by default.
+func converts(list []string) []string {
+	if len(list) == 0 {
+		return []string{"USD"}
+	}
+	upper := make([]string, len(list))
+	for i, s := range list {
+		upper[i] = strings.ToUpper(strings.TrimSpace(s))
+	}
+	return upper
+}
+
+// joinErrors merges failed batches of id and symbol requests.
+func joinErrors(a, b error) error {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	ea, okA := a.(cmcproapi.BatchErrors)
+	eb, okB := b.(cmcproapi.BatchErrors)
+	if okA && okB {
+		return append(ea, eb...)
+	}
+	return errors.New(a.Error() + "; " + b.Error())
+}
+
+func unique(sorted []string) (list []string) {
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			list = append(list, s)
+		}
+	}
+	return
+}
@@ -0,0 +1,109 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package portfolio
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	cmcproapi "github.com/nikchis/cmc-proapi"
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6*math.Max(1, math.Abs(b))
+}
+
+func TestValue(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	srv.SetPrice(1, 10000)
+	srv.SetPrice(1027, 200)
+	cmc, _ := cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, cmcproapi.ApiVersion,
+		&http.Client{}, time.Second)
+	p := Portfolio{
+		{Symbol: "btc", Quantity: 0.5, Cost: 4000},
+		{Id: 1027, Quantity: 10, Cost: 2500},
+		{Id: 1027, Quantity: 5, Cost: 900, CostCurrency: "EUR"},
+	}
+	report, err := p.Value(context.Background(), cmc, Options{Convert: []string{"usd", "EUR"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Holdings) != 3 {
+		t.Fatalf("unexpected holdings: %+v", report.Holdings)
+	}
+	btc := report.Holdings[0].Values["USD"]
+	if !near(btc.Value, 5000) || !near(btc.PnL, 1000) || !near(btc.PnLPercent, 25) {
+		t.Errorf("unexpected BTC valuation: %+v", btc)
+	}
+	if want := 5000 - 5000/(1+0.328918/100); !near(btc.Change24h, want) {
+		t.Errorf("Change24h = %v, want %v", btc.Change24h, want)
+	}
+	usd := report.Totals["USD"]
+	if !near(usd.Value, 8000) || !near(usd.Cost, 6500) || !near(usd.PnL, 500) {
+		t.Errorf("unexpected USD total: %+v", usd)
+	}
+	if !near(btc.Weight, 62.5) || !near(report.Holdings[1].Values["USD"].Weight, 25) {
+		t.Errorf("unexpected weights: %v, %v", btc.Weight, report.Holdings[1].Values["USD"].Weight)
+	}
+	lot := report.Holdings[2].Values
+	if lot["USD"].PnL != 0 || lot["EUR"].Cost != 900 || !near(lot["EUR"].Value, 1000/1.0988) {
+		t.Errorf("unexpected EUR lot: %+v", lot)
+	}
+	if eur := report.Totals["EUR"]; !near(eur.Value, 8000/1.0988) || eur.Cost != 900 {
+		t.Errorf("unexpected EUR total: %+v", eur)
+	}
+
+	// missing quotes leave the holding unvalued
+	srv.SetFault(cmcproapi.EndpointCurrencyQuotesLatest, cmcproapitest.Fault{
+		ErrorCode: cmcproapitest.ErrorCodeMinuteRateLimit, Count: 1})
+	report, err = p.Value(context.Background(), cmc, Options{})
+	if err == nil || len(report.Holdings[1].Values) != 0 || report.Holdings[0].Values["USD"].Value == 0 {
+		t.Errorf("unexpected partial report: %+v, %v", report, err)
+	}
+}
+
+func TestRevalue(t *testing.T) {
+	listings := cmcproapi.CurrencyListingMap{
+		"1": {Id: 1, Symbol: "BTC", Quote: &cmcproapi.CurrencyQuoteMap{"USD": {Price: 100}}},
+	}
+	report := Portfolio{{Symbol: "BTC", Quantity: 2}, {Id: 1, Quantity: 1}}.Revalue(listings)
+	if report.Totals["USD"].Value != 300 || !near(report.Holdings[0].Values["USD"].Weight, 200.0/3) {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestValueBatches(t *testing.T) {
+	srv := cmcproapitest.NewServer()
+	defer srv.Close()
+	cmc, _ := cmcproapi.NewCustom(cmcproapitest.APIKey, srv.URL, cmcproapi.ApiVersion,
+		&http.Client{}, time.Second)
+	p := Portfolio{{Id: 1, Quantity: 1}, {Symbol: "ltc", Quantity: 1}, {Symbol: "ETH", Quantity: 1}}
+	opt := Options{Batch: cmcproapi.BatchOptions{Size: 1, Concurrency: 1}}
+	// fail the id batch and the first symbol batch
+	srv.SetFault(cmcproapi.EndpointCurrencyQuotesLatest, cmcproapitest.Fault{
+		ErrorCode: cmcproapitest.ErrorCodeInternal, Count: 2})
+	report, err := p.Value(context.Background(), cmc, opt)
+	errs, ok := err.(cmcproapi.BatchErrors)
+	if !ok || len(errs) != 2 || errs[0].Ids[0] != 1 || errs[1].Symbols[0] != "ETH" {
+		t.Fatalf("expected errors of both batches, got %v", err)
+	}
+	if calls := srv.Calls(cmcproapi.EndpointCurrencyQuotesLatest); calls != 3 {
+		t.Errorf("sent %d requests", calls)
+	}
+	if len(report.Holdings[2].Values) != 0 || len(report.Holdings[1].Values) == 0 {
+		t.Errorf("unexpected holdings %+v", report.Holdings)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = (Portfolio{{Symbol: "BTC", Quantity: 1}}).Value(ctx, cmc, opt); err == nil {
+		t.Error("expected error of canceled context")
+	}
+}
@@ -0,0 +1,400 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	QueryLimitMax   = 5000
+	QueryAmountMin  = 1e-8
+	QueryAmountMax  = 1e9
+	QueryConvertMax = 120
+	QueryCountMax   = 10000
+)
+
+// CurrencyMapQuery holds typed parameters of the cryptocurrency/map endpoint.
+type CurrencyMapQuery struct {
+	ListingStatus []ListingStatus
+	Start         int
+	Limit         int
+	Sort          string
+	Symbols       []string
+}
+
+// CurrencyInfoQuery holds typed parameters of the cryptocurrency/info endpoint.
+// Exactly one of Ids, Symbols, Slugs or Address must be set.
+type CurrencyInfoQuery struct {
+	Ids     []int
+	Symbols []string
+	Slugs   []string
+	Address string
+}
+
+// CurrencyListingsQuery holds typed parameters of the cryptocurrency/listings/latest endpoint.
+type CurrencyListingsQuery struct {
+	Start      int
+	Limit      int
+	Sort       string
+	SortDir    string
+	Convert    []string
+	ConvertIds []int
+}
+
+// CurrencyQuotesQuery holds typed parameters of the cryptocurrency/quotes/latest endpoint.
+// Exactly one of Ids, Symbols or Slugs must be set.
+type CurrencyQuotesQuery struct {
+	Ids        []int
+	Symbols    []string
+	Slugs      []string
+	Convert    []string
+	ConvertIds []int
+}
+
+// GlobalQuotesQuery holds typed parameters of the global-metrics/quotes/latest endpoint.
+type GlobalQuotesQuery struct {
+	Convert    []string
+	ConvertIds []int
+}
+
+// PriceConversionQuery holds typed parameters of the tools/price-conversion endpoint.
+// Exactly one of Id or Symbol must be set. A non-zero Time requests historical
+// conversion at that time instead of the latest one.
+type PriceConversionQuery struct {
+	Amount     float64
+	Id         int
+	Symbol     string
+	Time       time.Time
+	Convert    []string
+	ConvertIds []int
+}
+
+// CurrencyQuotesHistoricalQuery holds typed parameters of the
+// cryptocurrency/quotes/historical endpoint, e.g. for Client.Get. Exactly one
+// of Ids, Symbols or Slugs must be set, zero times of the range are omitted.
+type CurrencyQuotesHistoricalQuery struct {
+	Ids        []int
+	Symbols    []string
+	Slugs      []string
+	TimeStart  time.Time
+	TimeEnd    time.Time
+	Count      int
+	Interval   string
+	Convert    []string
+	ConvertIds []int
+}
+
+// CurrencyOHLCVHistoricalQuery holds typed parameters of the
+// cryptocurrency/ohlcv/historical endpoint, e.g. for Client.Get. Exactly one
+// of Ids, Symbols or Slugs must be set, zero times of the range are omitted.
+type CurrencyOHLCVHistoricalQuery struct {
+	Ids        []int
+	Symbols    []string
+	Slugs      []string
+	TimePeriod string
+	TimeStart  time.Time
+	TimeEnd    time.Time
+	Count      int
+	Interval   string
+	Convert    []string
+	ConvertIds []int
+}
+
+// GlobalQuotesHistoricalQuery holds typed parameters of the
+// global-metrics/quotes/historical endpoint, e.g. for Client.Get. Zero times
+// of the range are omitted.
+type GlobalQuotesHistoricalQuery struct {
+	TimeStart  time.Time
+	TimeEnd    time.Time
+	Count      int
+	Interval   string
+	Convert    []string
+	ConvertIds []int
+}
+
+// Values validates the query and returns its URL encoded form.
+func (q *CurrencyMapQuery) Values() (v url.Values, err error) {
+	v = url.Values{}
+	if err = setPage(v, q.Start, q.Limit); err != nil {
+		return nil, err
+	}
+	if len(q.ListingStatus) != 0 {
+		list := make([]string, len(q.ListingStatus))
+		for i, s := range q.ListingStatus {
+			list[i] = string(s)
+		}
+		v.Set("listing_status", strings.Join(list, ","))
+	}
+	if q.Sort != "" {
+		v.Set("sort", q.Sort)
+	}
+	if symbols := joinStrings(q.Symbols); symbols != "" {
+		v.Set("symbol", symbols)
+	}
+	return
+}
+
+// Values validates the query and returns its URL encoded form.
+func (q *CurrencyInfoQuery) Values() (v url.Values, err error) {
+	v = url.Values{}
+	if q.Address != "" {
+		if len(q.Ids) != 0 || len(q.Symbols) != 0 || len(q.Slugs) != 0 {
+			return nil, errors.New(ltMsgQueryAddress)
+		}
+		v.Set("address", q.Address)
+		return
+	}
+	if err = setIdentity(v, q.Ids, q.Symbols, q.Slugs); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Values validates the query and returns its URL encoded form.
+func (q *CurrencyListingsQuery) Values() (v url.Values, err error) {
+	v = url.Values{}
+	if err = setPage(v, q.Start, q.Limit); err != nil {
+		return nil, err
+	}
+	if q.Sort != "" {
+		v.Set("sort", q.Sort)
+	}
+	if q.SortDir != "" {
+		if q.SortDir != "asc" && q.SortDir != "desc" {
+			return nil, errors.New(ltMsgQuerySortDir)
+		}
+		v.Set("sort_dir", q.SortDir)
+	}
+	if err = setConvert(v, q.Convert, q.ConvertIds); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Values validates the query and returns its URL encoded form.
+func (q *CurrencyQuotesQuery) Values() (v url.Values, err error) {
+	v = url.Values{}
+	if err = setIdentity(v, q.Ids, q.Symbols, q.Slugs); err != nil {
+		return nil, err
+	}
+	if err = setConvert(v, q.Convert, q.ConvertIds); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Values validates the query and returns its URL encoded form.
+func (q *GlobalQuotesQuery) Values() (v url.Values, err error) {
+	v = url.Values{}
+	if err = setConvert(v, q.Convert, q.ConvertIds); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Values validates the query and returns its URL encoded form.
+func (q *PriceConversionQuery) Values() (v url.Values, err error) {
+	v = url.Values{}
+	if q.Amount < QueryAmountMin || q.Amount > QueryAmountMax {
+		return nil, errors.New(ltMsgQueryAmount)
+	}
+	v.Set("amount", strconv.FormatFloat(q.Amount, 'f', -1, 64))
+	switch {
+	case q.Id != 0 && q.Symbol != "":
+		return nil, errors.New(ltMsgQueryExclusive)
+	case q.Id > 0:
+		v.Set("id", strconv.Itoa(q.Id))
+	case q.Id < 0:
+		return nil, errors.New(ltMsgQueryId)
+	case q.Symbol != "":
+		v.Set("symbol", q.Symbol)
+	default:
+		return nil, errors.New(ltMsgQueryIdentity)
+	}
+	if !q.Time.IsZero() {
+		v.Set("time", q.Time.UTC().Format(time.RFC3339))
+	}
+	if err = setConvert(v, q.Convert, q.ConvertIds); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Values validates the query and returns its URL encoded form.
+func (q *CurrencyQuotesHistoricalQuery) Values() (v url.Values, err error) {
+	v = url.Values{}
+	if err = setIdentity(v, q.Ids, q.Symbols, q.Slugs); err != nil {
+		return nil, err
+	}
+	if err = setHistory(v, q.TimeStart, q.TimeEnd, q.Count, q.Interval); err != nil {
+		return nil, err
+	}
+	if err = setConvert(v, q.Convert, q.ConvertIds); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Values validates the query and returns its URL encoded form.
+func (q *CurrencyOHLCVHistoricalQuery) Values() (v url.Values, err error) {
+	v = url.Values{}
+	if err = setIdentity(v, q.Ids, q.Symbols, q.Slugs); err != nil {
+		return nil, err
+	}
+	if q.TimePeriod != "" {
+		if q.TimePeriod != "daily" && q.TimePeriod != "hourly" {
+			return nil, errors.New(ltMsgQueryTimePeriod)
+		}
+		v.Set("time_period", q.TimePeriod)
+	}
+	if err = setHistory(v, q.TimeStart, q.TimeEnd, q.Count, q.Interval); err != nil {
+		return nil, err
+	}
+	if err = setConvert(v, q.Convert, q.ConvertIds); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Values validates the query and returns its URL encoded form.
+func (q *GlobalQuotesHistoricalQuery) Values() (v url.Values, err error) {
+	v = url.Values{}
+	if err = setHistory(v, q.TimeStart, q.TimeEnd, q.Count, q.Interval); err != nil {
+		return nil, err
+	}
+	if err = setConvert(v, q.Convert, q.ConvertIds); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// setPage validates and sets start and limit parameters, zero values are omitted.
+func setPage(v url.Values, start, limit int) error {
+	if start < 0 {
+		return errors.New(ltMsgQueryStart)
+	}
+	if limit < 0 || limit > QueryLimitMax {
+		return errors.New(ltMsgQueryLimit)
+	}
+	if start > 0 {
+		v.Set("start", strconv.Itoa(start))
+	}
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+	return nil
+}
+
+// setHistory validates and sets time_start, time_end, count and interval
+// parameters of historical endpoints, zero values are omitted.
+func setHistory(v url.Values, start, end time.Time, count int, interval string) error {
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return errors.New(ltMsgQueryTimeRange)
+	}
+	if count < 0 || count > QueryCountMax {
+		return errors.New(ltMsgQueryCount)
+	}
+	if !start.IsZero() {
+		v.Set("time_start", start.UTC().Format(time.RFC3339))
+	}
+	if !end.IsZero() {
+		v.Set("time_end", end.UTC().Format(time.RFC3339))
+	}
+	if count > 0 {
+		v.Set("count", strconv.Itoa(count))
+	}
+	if interval != "" {
+		v.Set("interval", interval)
+	}
+	return nil
+}
+
+// setIdentity validates and sets exactly one of id, symbol or slug parameters.
+func setIdentity(v url.Values, ids []int, symbols, slugs []string) error {
+	// blank symbols and slugs are dropped, so a list of them identifies nothing
+	symbols, slugs = trimStrings(symbols), trimStrings(slugs)
+	n := 0
+	for _, l := range []int{len(ids), len(symbols), len(slugs)} {
+		if l != 0 {
+			n++
+		}
+	}
+	switch {
+	case n == 0:
+		return errors.New(ltMsgQueryIdentity)
+	case n > 1:
+		return errors.New(ltMsgQueryExclusive)
+	}
+	if len(ids) != 0 {
+		s, err := joinIds(ids)
+		if err != nil {
+			return err
+		}
+		v.Set("id", s)
+	}
+	if len(symbols) != 0 {
+		v.Set("symbol", strings.Join(symbols, ","))
+	}
+	if len(slugs) != 0 {
+		v.Set("slug", strings.ToLower(strings.Join(slugs, ",")))
+	}
+	return nil
+}
+
+// setConvert validates and sets either convert or convert_id parameter.
+func setConvert(v url.Values, convert []string, convertIds []int) error {
+	// blank currencies are dropped, so a list of them converts to nothing
+	convert = trimStrings(convert)
+	if len(convert) != 0 && len(convertIds) != 0 {
+		return errors.New(ltMsgQueryConvert)
+	}
+	if len(convert)+len(convertIds) > QueryConvertMax {
+		return errors.New(ltMsgQueryConvertMax)
+	}
+	if len(convert) != 0 {
+		v.Set("convert", strings.Join(convert, ","))
+	}
+	if len(convertIds) != 0 {
+		s, err := joinIds(convertIds)
+		if err != nil {
+			return err
+		}
+		v.Set("convert_id", s)
+	}
+	return nil
+}
+
+// joinIds returns comma-separated list of positive ids.
+func joinIds(ids []int) (string, error) {
+	list := make([]string, len(ids))
+	for i, id := range ids {
+		if id <= 0 {
+			return "", errors.New(ltMsgQueryId)
+		}
+		list[i] = strconv.Itoa(id)
+	}
+	return strings.Join(list, ","), nil
+}
+
+// joinStrings returns comma-separated list of trimmed non-empty values.
+func joinStrings(values []string) string {
+	return strings.Join(trimStrings(values), ",")
+}
+
+// trimStrings returns values with surrounding whitespace trimmed and blank ones dropped.
+func trimStrings(values []string) []string {
+	list := make([]string, 0, len(values))
+	for _, s := range values {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,143 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCurrencyMapQueryValues(t *testing.T) {
+	q := CurrencyMapQuery{
+		ListingStatus: []ListingStatus{ListingActive, ListingInactive},
+		Start:         1,
+		Limit:         100,
+		Symbols:       []string{"BTC", " ETH "},
+	}
+	v, err := q.Values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Encode(); got !=
+		"limit=100&listing_status=active%2Cinactive&start=1&symbol=BTC%2CETH" {
+		t.Errorf("unexpected query: %s", got)
+	}
+	q.Limit = QueryLimitMax + 1
+	if _, err = q.Values(); err == nil {
+		t.Error("expected limit error")
+	}
+}
+
+func TestCurrencyQuotesQueryValues(t *testing.T) {
+	q := CurrencyQuotesQuery{Ids: []int{1, 1027}, ConvertIds: []int{2781}}
+	v, err := q.Values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Encode(); got != "convert_id=2781&id=1%2C1027" {
+		t.Errorf("unexpected query: %s", got)
+	}
+	for _, q := range []CurrencyQuotesQuery{
+		{},
+		{Ids: []int{1}, Symbols: []string{"BTC"}},
+		{Ids: []int{0}},
+		{Ids: []int{1}, Convert: []string{"USD"}, ConvertIds: []int{2781}},
+	} {
+		if _, err = q.Values(); err == nil {
+			t.Errorf("expected error for %+v", q)
+		}
+	}
+}
+
+func TestPriceConversionQueryValues(t *testing.T) {
+	q := PriceConversionQuery{Amount: 2.5, Symbol: "BTC", Convert: []string{"USD", "EUR"}}
+	v, err := q.Values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Encode(); got != "amount=2.5&convert=USD%2CEUR&symbol=BTC" {
+		t.Errorf("unexpected query: %s", got)
+	}
+	q.Time = time.Date(2019, 1, 1, 3, 0, 0, 0, time.FixedZone("MSK", 3*3600))
+	if v, _ = q.Values(); v.Get("time") != "2019-01-01T00:00:00Z" {
+		t.Errorf("unexpected time: %s", v.Get("time"))
+	}
+	for _, q := range []PriceConversionQuery{
+		{Amount: 1},
+		{Amount: 0, Id: 1},
+		{Amount: 1, Id: 1, Symbol: "BTC"},
+	} {
+		if _, err = q.Values(); err == nil {
+			t.Errorf("expected error for %+v", q)
+		}
+	}
+}
+
+func TestGetByQueryValidatesLocally(t *testing.T) {
+	cmc, _ := NewCustom(TestApiKey, "http://127.0.0.1:0", ApiVersion)
+	if _, err := cmc.GetCurrencyInfoByQuery(context.Background(), CurrencyInfoQuery{}); err == nil ||
+		err.Error() != ltMsgQueryIdentity {
+		t.Errorf("expected local validation error, got %v", err)
+	}
+	for _, q := range []CurrencyQuotesQuery{
+		{Symbols: []string{" "}},
+		{Slugs: []string{"", "\t"}},
+	} {
+		if _, err := q.Values(); err == nil || err.Error() != ltMsgQueryIdentity {
+			t.Errorf("expected identity error for %+v, got %v", q, err)
+		}
+	}
+	q := CurrencyQuotesQuery{Symbols: []string{" btc", "", "ETH "}}
+	if v, err := q.Values(); err != nil || v.Get("symbol") != "btc,ETH" {
+		t.Errorf("unexpected symbols %v, %v", v, err)
+	}
+}
+
+func TestHistoricalQueryValues(t *testing.T) {
+	start := time.Date(2019, 1, 1, 3, 0, 0, 0, time.FixedZone("MSK", 3*3600))
+	q := CurrencyOHLCVHistoricalQuery{
+		Ids:        []int{1},
+		TimePeriod: "daily",
+		TimeStart:  start,
+		TimeEnd:    start.AddDate(0, 0, 7),
+		Convert:    []string{"USD"},
+	}
+	v, err := q.Values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Encode(); got != "convert=USD&id=1&time_end=2019-01-08T00%3A00%3A00Z"+
+		"&time_period=daily&time_start=2019-01-01T00%3A00%3A00Z" {
+		t.Errorf("unexpected query: %s", got)
+	}
+	g := GlobalQuotesHistoricalQuery{TimeEnd: start, Count: 10, Interval: "1h"}
+	if v, _ = g.Values(); v.Encode() != "count=10&interval=1h&time_end=2019-01-01T00%3A00%3A00Z" {
+		t.Errorf("unexpected query: %s", v.Encode())
+	}
+	for _, q := range []CurrencyQuotesHistoricalQuery{
+		{},
+		{Ids: []int{1}, TimeStart: start, TimeEnd: start.Add(-time.Hour)},
+		{Ids: []int{1}, Count: QueryCountMax + 1},
+	} {
+		if _, err = q.Values(); err == nil {
+			t.Errorf("expected error for %+v", q)
+		}
+	}
+	if _, err = (&CurrencyOHLCVHistoricalQuery{Ids: []int{1}, TimePeriod: "weekly"}).Values(); err == nil {
+		t.Error("expected time_period error")
+	}
+}
+
+func TestBlankListsOmitted(t *testing.T) {
+	m := CurrencyMapQuery{Symbols: []string{" ", ""}}
+	if v, err := m.Values(); err != nil || v.Encode() != "" {
+		t.Errorf("unexpected map query %v, %v", v, err)
+	}
+	q := CurrencyQuotesQuery{Ids: []int{1}, Convert: []string{"", " "}, ConvertIds: []int{2781}}
+	if v, err := q.Values(); err != nil || v.Encode() != "convert_id=2781&id=1" {
+		t.Errorf("unexpected quotes query %v, %v", v, err)
+	}
+}
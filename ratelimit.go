@@ -0,0 +1,55 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit is the maximum number of requests per minute sent by Client,
+// e.g. NewCustom(apiKey, RateLimit(30)) for the Basic plan.
+type RateLimit int
+
+// rateLimiter spaces requests evenly within a minute.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(rpm RateLimit) *rateLimiter {
+	if rpm <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Minute / time.Duration(rpm)}
+}
+
+// wait blocks until the next request is allowed or ctx is done.
+func (rl *rateLimiter) wait(ctx context.Context) error {
+	if rl == nil {
+		return nil
+	}
+	rl.mu.Lock()
+	now := time.Now()
+	if rl.next.Before(now) {
+		rl.next = now
+	}
+	delay := rl.next.Sub(now)
+	rl.next = rl.next.Add(rl.interval)
+	rl.mu.Unlock()
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
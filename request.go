@@ -5,12 +5,14 @@
 package cmcproapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -34,38 +36,122 @@ func (c *Client) do(request *http.Request) (*http.Response, error) {
 	}
 }
 
-// call prepares and process HTTP request to endpoint.
-func (c *Client) call(endpoint string, query url.Values) (result []byte, err error) {
+// header returns headers of requests to the API authorized by key.
+func header(key string) http.Header {
+	h := http.Header{}
+	h.Set("Accept", "application/json")
+	h.Set(ltCmcProApiKeyX, key)
+	return h
+}
+
+// call prepares and process HTTP request to endpoint of the API version authorized by key.
+func (c *Client) call(ctx context.Context, key, version, endpoint string,
+	query url.Values) (result []byte, code int, err error) {
 	var req *http.Request
 	var resp *http.Response
-	rawurl := fmt.Sprintf("%s/%s/%s", c.apiDomain, c.apiVersion, endpoint)
-	if req, err = http.NewRequest("GET", rawurl, nil); err != nil {
+	rawurl := fmt.Sprintf("%s/%s/%s", c.apiDomain, version, endpoint)
+	if req, err = http.NewRequestWithContext(ctx, "GET", rawurl, nil); err != nil {
 		return
 	}
-	req.Header.Set("Accept", "application/json")
-	req.Header.Add(ltCmcProApiKeyX, c.apiKey)
+	req.Header = header(key)
 	req.URL.RawQuery = query.Encode()
 	if resp, err = c.do(req); err != nil {
 		return
 	}
+	defer resp.Body.Close()
+	code = resp.StatusCode
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized &&
 		resp.StatusCode != http.StatusPaymentRequired && resp.StatusCode != http.StatusForbidden &&
 		resp.StatusCode != http.StatusTooManyRequests {
 		err = errors.New(resp.Status)
 		return
 	}
-	defer resp.Body.Close()
 	if result, err = ioutil.ReadAll(resp.Body); err != nil {
 		return
 	}
 	return
 }
 
+// roundTrip waits for the rate limiter and returns decoded response of endpoint.
+//
+// The key is taken from the KeySource if any, which gets ctx so that a slow
+// source is aborted along with the request. With a KeyPool the request is
+// retried with other keys while it fails because of the key used.
+func (c *Client) roundTrip(
+	ctx context.Context, version, endpoint string, query url.Values) (response Response, err error) {
+	var tried map[string]bool
+	for attempt := 1; ; attempt++ {
+		key := c.apiKey
+		if c.keys != nil {
+			var ok bool
+			if key, ok = c.keys.pick(tried); !ok {
+				if attempt == 1 {
+					err = errors.New(ltMsgKeysBenched)
+				}
+				return
+			}
+			if tried == nil {
+				tried = make(map[string]bool)
+			}
+			tried[key] = true
+		} else if c.source != nil {
+			if key, err = c.source.Key(ctx); err != nil {
+				return
+			}
+		}
+		if err = c.limiter.wait(ctx); err != nil {
+			return
+		}
+		var code int
+		response, code, err = c.attempt(ctx, key, version, endpoint, query, attempt)
+		if c.keys == nil || !c.keys.report(key, code, response.Status) {
+			return
+		}
+	}
+}
+
+// attempt returns decoded response of endpoint requested with key, the
+// request is reported to hooks.
+func (c *Client) attempt(ctx context.Context, key, version, endpoint string,
+	query url.Values, n int) (response Response, code int, err error) {
+	var info *RequestInfo
+	if len(c.hooks) != 0 {
+		info = &RequestInfo{Method: "GET", Version: version, Endpoint: endpoint, Query: query,
+			Header: redactHeader(header(key)), Attempt: n}
+		for _, h := range c.hooks {
+			h.BeforeRequest(ctx, info)
+		}
+	}
+	started := time.Now()
+	var rawresponse []byte
+	rawresponse, code, err = c.call(ctx, key, version, endpoint, query)
+	if err == nil {
+		err = json.Unmarshal(rawresponse, &response)
+	}
+	response.Status.Sandbox = c.Sandbox()
+	if info != nil {
+		resp := &ResponseInfo{HTTPStatus: code, Status: response.Status,
+			Elapsed: time.Since(started), Err: err}
+		if err == nil {
+			resp.Err = response.handleStatus()
+		}
+		for _, h := range c.hooks {
+			h.AfterResponse(ctx, info, resp)
+		}
+	}
+	return
+}
+
 // handleRequest returns raw JSON data after succesfull request to API and handling response status.
+//
+// Besides endpoint and query it optionally accepts context.Context of the request,
+// *ResponseStatus which is filled with the status of response and a second string
+// overriding the API version of the Client.
 func (c *Client) handleRequest(args ...interface{}) (json.RawMessage, error) {
+	var ctx context.Context
 	var query *url.Values
-	var endpoint string
-	var rawresponse []byte
+	var status *ResponseStatus
+	var endpoint, version string
 	var response Response
 	var err error
 	if args == nil {
@@ -77,25 +163,72 @@ func (c *Client) handleRequest(args ...interface{}) (json.RawMessage, error) {
 		case string:
 			if endpoint == "" {
 				endpoint = val
+			} else if version == "" {
+				version = val
 			}
 		case *url.Values:
 			query = val
+		case *ResponseStatus:
+			status = val
+		case context.Context:
+			ctx = val
+		case nil:
 		default:
 			err = errors.New(ltMsgUnsupArgType)
 			return nil, err
 		}
 	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	if query == nil {
 		query = &url.Values{}
 	}
-	if rawresponse, err = c.call(endpoint, *query); err != nil {
-		return nil, err
+	if version == "" {
+		version = c.apiVersion
 	}
-	if err = json.Unmarshal(rawresponse, &response); err != nil {
+	if response, err = c.cachedFetch(ctx, version, endpoint, *query); err != nil {
 		return nil, err
 	}
+	if status != nil {
+		*status = response.Status
+	}
 	if err = response.handleStatus(); err != nil {
 		return nil, err
 	}
 	return response.Data, nil
 }
+
+// decodeRequest acts identically to handleRequest, except that it decodes data into result.
+func (c *Client) decodeRequest(result interface{}, args ...interface{}) error {
+	raw, err := c.handleRequest(args...)
+	if err != nil {
+		return err
+	}
+	return c.unmarshal(raw, result)
+}
+
+// Get requests an endpoint not covered by Client and decodes data of the
+// response into out, unless it is nil, e.g.
+//
+//	var data json.RawMessage
+//	status, err := cmc.Get(ctx, "v2", "cryptocurrency/ohlcv/historical", q, &data)
+//
+// Empty version is the version of the Client. The request is authorized, rate
+// limited, failed over, reported to hooks and cached if CacheTTL lists the
+// endpoint like any other. The status is returned also if the API reported an error.
+func (c *Client) Get(ctx context.Context, version, endpoint string,
+	query url.Values, out interface{}) (status ResponseStatus, err error) {
+	if endpoint = strings.Trim(endpoint, "/"); endpoint == "" {
+		err = errors.New(ltMsgEmptyEndpoint)
+		return
+	}
+	var raw json.RawMessage
+	if raw, err = c.handleRequest(ctx, endpoint, strings.Trim(version, "/"), &query, &status); err != nil {
+		return
+	}
+	if out != nil {
+		err = c.unmarshal(raw, out)
+	}
+	return
+}
@@ -0,0 +1,66 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+func TestGet(t *testing.T) {
+	var version string
+	hook := HookFuncs{Before: func(ctx context.Context, req *RequestInfo) {
+		version = req.Version
+	}}
+	cmc, _ := NewCustom(cmcproapitest.APIKey, testServer.URL, TestApiVersion,
+		&http.Client{}, time.Second, hook)
+	q := url.Values{"id": {"1027"}, "convert": {"USD"}}
+	var data map[string]CurrencyListing
+	status, err := cmc.Get(context.Background(), "v2", "/cryptocurrency/quotes/latest/", q, &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data["1027"].Symbol != "ETH" || status.CreditCount != 1 || version != "v2" {
+		t.Errorf("unexpected response %+v, status %+v, version %s", data, status, version)
+	}
+	if _, err = cmc.Get(context.Background(), "", EndpointKeyInfo, nil, nil); err != nil ||
+		version != TestApiVersion {
+		t.Errorf("unexpected error %v or version %s", err, version)
+	}
+
+	testServer.SetFault(EndpointKeyInfo, cmcproapitest.Fault{
+		ErrorCode: cmcproapitest.ErrorCodeMinuteRateLimit, Count: 1})
+	status, err = cmc.Get(context.Background(), "", EndpointKeyInfo, nil, nil)
+	if err == nil || status.ErrorCode != cmcproapitest.ErrorCodeMinuteRateLimit {
+		t.Errorf("expected rate limit status, got %+v, %v", status, err)
+	}
+	if _, err = cmc.Get(context.Background(), "v1", "/", nil, nil); err == nil {
+		t.Error("expected empty endpoint error")
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path + "?" + r.URL.RawQuery
+		fmt.Fprint(w, `{"data":{"ok":true},"status":{"error_code":0}}`)
+	}))
+	defer srv.Close()
+	cmc, _ := NewCustom(TestApiKey, srv.URL, TestApiVersion, &http.Client{}, time.Second)
+	var data struct{ Ok bool }
+	if _, err := cmc.Get(context.Background(), "v3", "fiat/map", url.Values{"limit": {"2"}}, &data); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/v3/fiat/map?limit=2" || !data.Ok {
+		t.Errorf("requested %s, decoded %+v", path, data)
+	}
+}
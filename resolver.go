@@ -0,0 +1,282 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver maps symbols, slugs, names and token addresses of cryptocurrencies
+// to CoinMarketCap ids.
+//
+// Symbols and names are not unique, so every key resolves to candidates ranked
+// with active cryptocurrencies first and then by cmc_rank. The index is built
+// from cryptocurrency/map of all listing statuses and can be saved and loaded
+// to start without the API.
+//
+//	r := cmcproapi.NewResolver(cmc)
+//	if err := r.Refresh(ctx); err != nil {
+//		return err
+//	}
+//	btc, err := r.Resolve("BTC")
+type Resolver struct {
+	// OnError is called when a periodic refresh fails, errors are ignored if nil.
+	OnError func(error)
+
+	client *Client
+
+	mu         sync.RWMutex
+	currencies map[int]CurrencyMap
+	symbols    map[string][]int
+	slugs      map[string][]int
+	names      map[string][]int
+	addresses  map[string][]int
+	updated    time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// resolverIdPrefix marks keys of Resolver which are ids.
+const resolverIdPrefix = "id:"
+
+// resolverState is the serialized form of Resolver.
+type resolverState struct {
+	Updated    time.Time     `json:"updated"`
+	Currencies []CurrencyMap `json:"currencies"`
+}
+
+// NewResolver returns an empty Resolver refreshed using the client.
+func NewResolver(c *Client) *Resolver {
+	r := &Resolver{client: c}
+	r.Update(nil, time.Time{})
+	return r
+}
+
+// Refresh rebuilds the index from cryptocurrency/map.
+func (r *Resolver) Refresh(ctx context.Context) error {
+	var list []CurrencyMap
+	it := r.client.IterCurrencyMap(ctx, CurrencyMapQuery{
+		ListingStatus: []ListingStatus{ListingActive, ListingInactive, ListingUntracked},
+	}, PageLimit{})
+	for it.Next() {
+		list = append(list, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	r.Update(list, time.Now())
+	return nil
+}
+
+// Update replaces the index with currencies as of the given time.
+func (r *Resolver) Update(currencies []CurrencyMap, updated time.Time) {
+	byId := make(map[int]CurrencyMap, len(currencies))
+	symbols := map[string][]int{}
+	slugs := map[string][]int{}
+	names := map[string][]int{}
+	addresses := map[string][]int{}
+	for _, c := range currencies {
+		if _, ok := byId[c.Id]; ok {
+			continue
+		}
+		byId[c.Id] = c
+		symbols[normKey(c.Symbol)] = append(symbols[normKey(c.Symbol)], c.Id)
+		slugs[normKey(c.Slug)] = append(slugs[normKey(c.Slug)], c.Id)
+		names[normKey(c.Name)] = append(names[normKey(c.Name)], c.Id)
+		if c.Platform != nil && c.Platform.TokenAddress != "" {
+			key := normAddress(c.Platform.TokenAddress)
+			addresses[key] = append(addresses[key], c.Id)
+		}
+	}
+	for _, index := range []map[string][]int{symbols, slugs, names, addresses} {
+		for _, ids := range index {
+			rankIds(ids, byId)
+		}
+	}
+	r.mu.Lock()
+	r.currencies, r.symbols, r.slugs, r.names, r.addresses = byId, symbols, slugs, names, addresses
+	r.updated = updated
+	r.mu.Unlock()
+}
+
+// Updated returns the time the index was built.
+func (r *Resolver) Updated() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.updated
+}
+
+// Len returns the number of indexed cryptocurrencies.
+func (r *Resolver) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.currencies)
+}
+
+// Get returns the cryptocurrency by id.
+func (r *Resolver) Get(id int) (c CurrencyMap, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok = r.currencies[id]
+	return
+}
+
+// Candidates returns cryptocurrencies matching the key, best first. A key of
+// the form id:1027 matches the id only, since tickers may be numeric. Other
+// keys are tried as a token address, a slug, a symbol and a name in this
+// order, and the first kind that matches wins. Matching is case-insensitive
+// except for non-EVM addresses.
+func (r *Resolver) Candidates(key string) (result []CurrencyMap) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if strings.HasPrefix(key, resolverIdPrefix) {
+		if id, err := strconv.Atoi(key[len(resolverIdPrefix):]); err == nil {
+			if c, ok := r.currencies[id]; ok {
+				return []CurrencyMap{c}
+			}
+		}
+		return
+	}
+	if ids := r.addresses[normAddress(key)]; len(ids) > 0 {
+		return r.list(ids)
+	}
+	norm := normKey(key)
+	for _, index := range []map[string][]int{r.slugs, r.symbols, r.names} {
+		if ids := index[norm]; len(ids) > 0 {
+			return r.list(ids)
+		}
+	}
+	return
+}
+
+// list returns cryptocurrencies by ids.
+func (r *Resolver) list(ids []int) (result []CurrencyMap) {
+	result = make([]CurrencyMap, len(ids))
+	for i, id := range ids {
+		result[i] = r.currencies[id]
+	}
+	return
+}
+
+// Resolve returns the best cryptocurrency matching the key.
+func (r *Resolver) Resolve(key string) (c CurrencyMap, err error) {
+	list := r.Candidates(key)
+	if len(list) == 0 {
+		return c, errors.New(ltMsgResolveNotFound + ": " + key)
+	}
+	return list[0], nil
+}
+
+// ResolveIds returns ids of the best cryptocurrencies matching the keys.
+func (r *Resolver) ResolveIds(keys ...string) (ids []int, err error) {
+	ids = make([]int, len(keys))
+	for i, key := range keys {
+		var c CurrencyMap
+		if c, err = r.Resolve(key); err != nil {
+			return nil, err
+		}
+		ids[i] = c.Id
+	}
+	return
+}
+
+// Start refreshes the index in the background every interval until Stop is
+// called or ctx is done, a day by default. An index older than interval is
+// refreshed immediately.
+func (r *Resolver) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	r.Stop()
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	r.mu.Lock()
+	r.cancel, r.done = cancel, done
+	stale := time.Since(r.updated) >= interval
+	r.mu.Unlock()
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if stale {
+				if err := r.Refresh(ctx); err != nil && ctx.Err() == nil && r.OnError != nil {
+					r.OnError(err)
+				}
+			}
+			stale = true
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops background refreshes and waits for a running one to finish.
+func (r *Resolver) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Save writes the index as JSON.
+func (r *Resolver) Save(w io.Writer) error {
+	r.mu.RLock()
+	state := resolverState{Updated: r.updated, Currencies: make([]CurrencyMap, 0, len(r.currencies))}
+	for _, c := range r.currencies {
+		state.Currencies = append(state.Currencies, c)
+	}
+	r.mu.RUnlock()
+	sort.Slice(state.Currencies, func(i, j int) bool {
+		return state.Currencies[i].Id < state.Currencies[j].Id
+	})
+	return json.NewEncoder(w).Encode(&state)
+}
+
+// Load replaces the index with one written by Save.
+func (r *Resolver) Load(rd io.Reader) error {
+	var state resolverState
+	if err := json.NewDecoder(rd).Decode(&state); err != nil {
+		return err
+	}
+	r.Update(state.Currencies, state.Updated)
+	return nil
+}
+
+// normKey returns the case-insensitive form of an index key.
+func normKey(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// rankIds sorts ids with active cryptocurrencies first, then by cmc_rank with
+// unranked last, then by id.
+func rankIds(ids []int, currencies map[int]CurrencyMap) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := currencies[ids[i]], currencies[ids[j]]
+		if a.IsActive != b.IsActive {
+			return a.IsActive > b.IsActive
+		}
+		if a.Rank != b.Rank {
+			return b.Rank == 0 || (a.Rank != 0 && a.Rank < b.Rank)
+		}
+		return a.Id < b.Id
+	})
+}
@@ -0,0 +1,93 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResolver(t *testing.T) {
+	cmc, _ := NewTest()
+	r := NewResolver(cmc)
+	if err := r.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for key, id := range map[string]int{
+		"BTC":     1,
+		"btc":     1,
+		"ripple":  52,
+		"XRP":     52,
+		"id:1027": 1027,
+		"Bitcoin": 1,
+		"0xdAC17F958D2ee523a2206206994597C13D831ec7": 825,
+	} {
+		c, err := r.Resolve(key)
+		if err != nil || c.Id != id {
+			t.Errorf("Resolve(%q) = %d, %v, want %d", key, c.Id, err, id)
+		}
+	}
+	if list := r.Candidates("BTC"); len(list) != 2 || list[0].Id != 1 || list[1].Id != 3602 {
+		t.Errorf("Candidates(BTC) = %+v", list)
+	}
+	if _, err := r.Resolve("DOGE"); err == nil {
+		t.Error("Resolve(DOGE) succeeded")
+	}
+
+	numeric := NewResolver(cmc)
+	numeric.Update([]CurrencyMap{{Id: 42, Symbol: "HTG"}, {Id: 7, Symbol: "42"}}, time.Now())
+	if c, err := numeric.Resolve("42"); err != nil || c.Id != 7 {
+		t.Errorf("Resolve(42) = %d, %v, want symbol 42", c.Id, err)
+	}
+	if c, err := numeric.Resolve("id:42"); err != nil || c.Id != 42 {
+		t.Errorf("Resolve(id:42) = %d, %v", c.Id, err)
+	}
+	if _, err := numeric.Resolve("id:x"); err == nil {
+		t.Error("Resolve(id:x) succeeded")
+	}
+	if ids, err := r.ResolveIds("ETH", "bitcoin-free-cash"); err != nil || ids[0] != 1027 || ids[1] != 3602 {
+		t.Errorf("ResolveIds() = %v, %v", ids, err)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	offline := NewResolver(nil)
+	if err := offline.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if offline.Len() != r.Len() || !offline.Updated().Equal(r.Updated()) {
+		t.Errorf("Load() = %d currencies updated at %v", offline.Len(), offline.Updated())
+	}
+	if c, err := offline.Resolve("USDT"); err != nil || c.Platform == nil || c.Rank != 5 {
+		t.Errorf("Resolve(USDT) after Load() = %+v, %v", c, err)
+	}
+}
+
+func TestResolverStart(t *testing.T) {
+	cmc, _ := NewTest()
+	r := NewResolver(cmc)
+	calls := testServer.Calls(EndpointCurrencyMap)
+	r.Start(context.Background(), time.Hour)
+	deadline := time.Now().Add(time.Second)
+	for r.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	r.Stop()
+	if r.Len() == 0 || testServer.Calls(EndpointCurrencyMap) != calls+1 {
+		t.Errorf("Start() refreshed %d currencies with %d calls",
+			r.Len(), testServer.Calls(EndpointCurrencyMap)-calls)
+	}
+
+	// a fresh index is not refreshed on start
+	r.Start(context.Background(), time.Hour)
+	r.Stop()
+	if testServer.Calls(EndpointCurrencyMap) != calls+1 {
+		t.Error("Start() refreshed a fresh index")
+	}
+}
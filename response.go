@@ -17,12 +17,15 @@ type Response struct {
 	Status ResponseStatus  `json:"status"`
 }
 
+// ResponseStatus is the status object of a response. Sandbox is set by Client
+// for responses of the sandbox environment.
 type ResponseStatus struct {
 	Timestamp    jsonTime `json:"timestamp"`
 	ErrorCode    int      `json:"error_code"`
 	ErrorMessage string   `json:"error_message"`
 	Elapsed      int      `json:"elapsed"`
 	CreditCount  int      `json:"credit_count"`
+	Sandbox      bool     `json:"sandbox,omitempty"`
 }
 
 func (jt *jsonTime) UnmarshalJSON(data []byte) error {
@@ -43,8 +46,10 @@ func (jt *jsonTime) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func (jt *jsonTime) MarshalJSON() ([]byte, error) {
-	return json.Marshal((*time.Time)(jt).Format(time.RFC3339))
+// MarshalJSON has a value receiver, so that times are encoded also within
+// values which are not addressable, e.g. map elements.
+func (jt jsonTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(jt).Format(time.RFC3339))
 }
 
 // handleStatus checks the status of response.
@@ -0,0 +1,75 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import "reflect"
+
+// Sandbox environment of the API serving mock data for development.
+const (
+	SandboxApiDomain = "https://sandbox-api.coinmarketcap.com"
+	SandboxApiKey    = "b54bcf4d-1bca-4e8e-9a24-22ff2c3d462c"
+)
+
+// Environment is the API deployment used by Client, pass it to NewCustom.
+type Environment int
+
+const (
+	// Production is the default environment serving real data.
+	Production Environment = iota
+	// Sandbox makes NewCustom use the sandbox domain and, unless another key
+	// is given, the public sandbox key, e.g. NewCustom(Sandbox, RateLimit(30)).
+	// Responses of the sandbox carry ResponseStatus.Sandbox and decoded
+	// results the Sandbox field of their types.
+	Sandbox
+)
+
+// NewSandbox returns a Client of the sandbox environment authorized by the
+// public sandbox key.
+func NewSandbox() (c *Client, err error) {
+	return NewCustom(Sandbox)
+}
+
+// Sandbox reports whether the Client uses the sandbox environment, whose data
+// must not be mistaken for production values.
+func (c *Client) Sandbox() bool {
+	return c.env == Sandbox
+}
+
+// markSandbox sets the Sandbox field of results decoded from the sandbox, also
+// of those nested in pointers, slices and maps.
+func markSandbox(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			markSandbox(v.Elem())
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			switch {
+			case f.PkgPath != "":
+			case f.Name == "Sandbox" && f.Type.Kind() == reflect.Bool:
+				if field := v.Field(i); field.CanSet() {
+					field.SetBool(true)
+				}
+			default:
+				markSandbox(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			markSandbox(v.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// map elements are not addressable, update a copy
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			markSandbox(elem)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
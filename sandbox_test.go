@@ -0,0 +1,73 @@
+// Implementation of CoinMarketCap API
+// Copyright (c) 2019 Nikita Chisnikov <chisnikov@gmail.com>
+// Distributed under the MIT/X11 software license
+
+package cmcproapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nikchis/cmc-proapi/cmcproapitest"
+)
+
+func TestNewSandbox(t *testing.T) {
+	cmc, err := NewSandbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmc.Sandbox() || cmc.apiDomain != SandboxApiDomain || cmc.apiKey != SandboxApiKey ||
+		cmc.apiVersion != ApiVersion || cmc.httpClient == nil || cmc.httpTimeout == 0 {
+		t.Errorf("unexpected sandbox client %+v", cmc)
+	}
+	cmc, _ = NewCustom("own-key", Sandbox)
+	if cmc.apiKey != "own-key" || cmc.apiDomain != SandboxApiDomain {
+		t.Errorf("unexpected sandbox client with key %+v", cmc)
+	}
+	cmc, _ = NewCustom(TestApiKey)
+	if cmc.Sandbox() || cmc.apiDomain != ApiDomain {
+		t.Errorf("unexpected production client %+v", cmc)
+	}
+}
+
+func TestSandboxStatus(t *testing.T) {
+	// the fake server accepts the public sandbox key
+	cmc, _ := NewCustom("", testServer.URL, Sandbox)
+	if cmc.apiKey != cmcproapitest.APIKey {
+		t.Fatalf("unexpected key %s", cmc.apiKey)
+	}
+	var status ResponseStatus
+	if _, err := cmc.handleRequest(EndpointKeyInfo, &status); err != nil {
+		t.Fatal(err)
+	}
+	if !status.Sandbox {
+		t.Error("expected sandbox status")
+	}
+	prod, _ := NewTest()
+	if _, err := prod.handleRequest(EndpointKeyInfo, &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Sandbox {
+		t.Error("unexpected sandbox status")
+	}
+}
+
+func TestSandboxResults(t *testing.T) {
+	cmc, _ := NewCustom("", testServer.URL, Sandbox)
+	query := CurrencyQuotesQuery{Ids: []int{1}, Convert: []string{"USD"}}
+	result, err := cmc.GetCurrencyQuotesLatestByQuery(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := result["1"]; !l.Sandbox || !(*l.Quote)["USD"].Sandbox {
+		t.Errorf("unmarked sandbox result %+v", l)
+	}
+	if info, err := cmc.GetKeyInfo(); err != nil || !info.Sandbox {
+		t.Errorf("unmarked sandbox key info %+v, %v", info, err)
+	}
+	prod, _ := NewTest()
+	if result, err = prod.GetCurrencyQuotesLatestByQuery(context.Background(), query); err != nil ||
+		result["1"].Sandbox {
+		t.Errorf("marked production result %+v, %v", result, err)
+	}
+}
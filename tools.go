@@ -5,6 +5,7 @@
 package cmcproapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 )
@@ -16,6 +17,12 @@ type PriceConversion struct {
 	Amount      float64             `json:"amount"`
 	LastUpdated jsonTime            `json:"last_updated"`
 	Quote       *ConversionQuoteMap `json:"quote"`
+
+	// exact value decoded in NumberExact mode
+	AmountExact Decimal `json:"amount_exact,omitempty"`
+
+	// set on results of the sandbox environment
+	Sandbox bool `json:"sandbox,omitempty"`
 }
 
 type ConversionQuoteMap map[string]ConversionQuote
@@ -23,6 +30,9 @@ type ConversionQuoteMap map[string]ConversionQuote
 type ConversionQuote struct {
 	Price       float64  `json:"price"`
 	LastUpdated jsonTime `json:"last_updated"`
+
+	// exact value decoded in NumberExact mode
+	PriceExact Decimal `json:"price_exact,omitempty"`
 }
 
 // GetPriceConversionById converts an amount of one cryptocurrency or fiat currency into
@@ -37,7 +47,7 @@ func (c *Client) GetPriceConversionById(
 	if raw, err = c.handleRequest(ltUriToolsPriceConversion, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
 	return
 }
 
@@ -53,6 +63,26 @@ func (c *Client) GetPriceConversionBySymbol(
 	if raw, err = c.handleRequest(ltUriToolsPriceConversion, &q); err != nil {
 		return
 	}
-	err = json.Unmarshal(raw, &result)
+	err = c.unmarshal(raw, &result)
+	return
+}
+
+// GetPriceConversionByQuery acts identically to GetPriceConversionById, except that it
+// uses typed query which is validated before the request is sent, and ctx which
+// cancels the request.
+//
+// With Time set the conversion uses historical rates, LastUpdated of the result
+// and of its quotes then reports the time of the rates used.
+func (c *Client) GetPriceConversionByQuery(
+	ctx context.Context, query PriceConversionQuery) (result PriceConversion, err error) {
+	var raw json.RawMessage
+	var q url.Values
+	if q, err = query.Values(); err != nil {
+		return
+	}
+	if raw, err = c.handleRequest(ltUriToolsPriceConversion, &q, ctx); err != nil {
+		return
+	}
+	err = c.unmarshal(raw, &result)
 	return
 }
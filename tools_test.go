@@ -5,7 +5,10 @@
 package cmcproapi
 
 import (
+	"context"
+	"net/url"
 	"testing"
+	"time"
 )
 
 func TestGetPriceConversion(t *testing.T) {
@@ -16,3 +19,24 @@ func TestGetPriceConversion(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestGetPriceConversionHistorical(t *testing.T) {
+	cmc, _ := NewTest()
+	at := time.Date(2018, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	result, err := cmc.GetPriceConversionByQuery(context.Background(), PriceConversionQuery{
+		Amount: 1.5, Id: 1, Time: at, Convert: []string{"USD", "EUR"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !time.Time(result.LastUpdated).Equal(at) || result.Quote == nil || len(*result.Quote) != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	for convert, q := range *result.Quote {
+		if !time.Time(q.LastUpdated).Equal(at) || q.Price == 0 {
+			t.Errorf("unexpected %s quote: %+v", convert, q)
+		}
+	}
+	if ttl := cmc.ttl(EndpointToolsPriceConversion, url.Values{"time": {"1514804400"}}); ttl < 24*time.Hour {
+		t.Errorf("historical conversion ttl = %v", ttl)
+	}
+}